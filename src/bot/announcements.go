@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/log"
+)
+
+// announcements remembers where each guild's tracks are announced: the text
+// channel the session was started from, and the last announcement so it can
+// be deleted when the next one is posted.
+type announcements struct {
+	mu       sync.Mutex
+	channels map[string]string
+	last     map[string]*discordgo.Message
+}
+
+// rememberTextChannel records the text channel a guild's session was started
+// from.
+func (b *Bot) rememberTextChannel(guildID string, channelID string) {
+	b.announcements.mu.Lock()
+	defer b.announcements.mu.Unlock()
+	if b.announcements.channels == nil {
+		b.announcements.channels = make(map[string]string)
+	}
+	b.announcements.channels[guildID] = channelID
+}
+
+// forgetSession drops what was remembered about a guild's session once the
+// bot left.
+func (b *Bot) forgetSession(guildID string) {
+	b.announcements.mu.Lock()
+	defer b.announcements.mu.Unlock()
+	delete(b.announcements.channels, guildID)
+	delete(b.announcements.last, guildID)
+}
+
+// announcementChannel returns where a guild's tracks are announced: the
+// music channel if one is set, otherwise the channel the session was started
+// from.
+func (b *Bot) announcementChannel(guildID string, settings Settings) string {
+	if settings.MusicChannel != "" {
+		return settings.MusicChannel
+	}
+	b.announcements.mu.Lock()
+	defer b.announcements.mu.Unlock()
+	return b.announcements.channels[guildID]
+}
+
+// announce posts embed in the guild's announcement channel if announcements
+// are on, deleting the previous one when the guild asked for it.
+func (b *Bot) announce(guildID string, embed *discordgo.MessageEmbed) {
+	settings := b.Settings(guildID)
+	if !settings.AnnounceNowPlaying {
+		return
+	}
+	channelID := b.announcementChannel(guildID, settings)
+	if channelID == "" {
+		return
+	}
+
+	message, err := b.Session.ChannelMessageSendEmbed(channelID, embed)
+	if err != nil {
+		log.Error("Failed to post announcement: ", err)
+		return
+	}
+
+	b.announcements.mu.Lock()
+	if b.announcements.last == nil {
+		b.announcements.last = make(map[string]*discordgo.Message)
+	}
+	previous := b.announcements.last[guildID]
+	b.announcements.last[guildID] = message
+	b.announcements.mu.Unlock()
+
+	if previous != nil && settings.DeleteAnnouncements {
+		if err := b.Session.ChannelMessageDelete(previous.ChannelID, previous.ID); err != nil {
+			log.Debug("failed to delete previous announcement: ", err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+)
+
+// maxAttachmentSize is the largest upload the bot plays. Lavalink streams it
+// over HTTP, but huge files still take long to buffer.
+const maxAttachmentSize = 100 << 20
+
+// checkAttachment returns why an attachment cannot be played, if it can't.
+func checkAttachment(attachment *discordgo.MessageAttachment) error {
+	if attachment == nil {
+		return errors.New("the attachment is missing")
+	}
+	if !strings.HasPrefix(attachment.ContentType, "audio/") && !strings.HasPrefix(attachment.ContentType, "video/") {
+		return fmt.Errorf("%s is not an audio or video file", attachment.Filename)
+	}
+	if attachment.Size > maxAttachmentSize {
+		return fmt.Errorf("%s is larger than %d MB", attachment.Filename, maxAttachmentSize>>20)
+	}
+	return nil
+}
+
+// loadAttachment loads an uploaded file through the node's HTTP source. The
+// file has no metadata, so its name becomes the title and the uploader the
+// author.
+func (b *Bot) loadAttachment(attachment *discordgo.MessageAttachment, uploader *discordgo.User) (*loadedTracks, error) {
+	if err := checkAttachment(attachment); err != nil {
+		return nil, &loadError{Title: "Attachment Error", Icon: IconError, Color: ColorError,
+			Message: fmt.Sprintf("Cannot play this attachment: `%s`", err)}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.Config().LoadTracksTimeout())
+	defer cancel()
+
+	result, err := b.Lavalink.BestNode().LoadTracks(ctx, attachment.URL)
+	if err != nil {
+		return nil, searchError(err)
+	}
+
+	switch data := result.Data.(type) {
+	case lavalink.Track:
+		track := withTrackData(data, TrackData{
+			Title:  strings.TrimSuffix(attachment.Filename, path.Ext(attachment.Filename)),
+			Author: cmp.Or(uploader.GlobalName, uploader.Username),
+		})
+		return &loadedTracks{Tracks: []lavalink.Track{track}}, nil
+
+	case lavalink.Exception:
+		return nil, &loadError{Title: "Attachment Error", Icon: IconError, Color: ColorError,
+			Message: fmt.Sprintf("Could not play `%s`: `%s`", attachment.Filename, data)}
+	}
+
+	return nil, &loadError{Title: "Attachment Error", Icon: IconError, Color: ColorError,
+		Message: fmt.Sprintf("Could not play `%s`, make sure the node has the HTTP source enabled.", attachment.Filename)}
+}
+
+// PlayInVoice is a message command queueing the first playable file attached
+// to the message.
+func (b *Bot) PlayInVoice(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	message := data.Resolved.Messages[data.TargetID]
+
+	var (
+		attachment *discordgo.MessageAttachment
+		problem    error = errors.New("the message has no attachment")
+	)
+	for _, candidate := range message.Attachments {
+		if problem = checkAttachment(candidate); problem == nil {
+			attachment = candidate
+			break
+		}
+	}
+	if attachment == nil {
+		return b.SendResponse(event.Interaction, "Attachment Error",
+			fmt.Sprintf("%s Cannot play this message: `%s`", IconError, problem), ColorError)
+	}
+
+	return b.playWith(event, playModeQueue, false, func() (*loadedTracks, error) {
+		return b.loadAttachment(attachment, message.Author)
+	})
+}
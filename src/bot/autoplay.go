@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/log"
+)
+
+// autoplay keeps the music going after the queue ran out by playing a track
+// related to the one that just ended. If none is found the idle timer starts.
+func (b *Bot) autoplay(player disgolink.Player, previous lavalink.Track) {
+	guildID := player.GuildID().String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.Config().LoadTracksTimeout())
+	defer cancel()
+
+	next, ok := b.relatedTrack(ctx, guildID, previous)
+	if !ok {
+		b.startIdleTimer(guildID)
+		return
+	}
+	if err := player.Update(context.Background(), lavalink.WithTrack(next)); err != nil {
+		log.Error("Failed to autoplay next track: ", err)
+	}
+}
+
+// relatedTrack uses the YouTube mix of the previous track when there is one,
+// otherwise it searches for more tracks by the same author.
+func (b *Bot) relatedTrack(ctx context.Context, guildID string, previous lavalink.Track) (lavalink.Track, bool) {
+	identifier := applySearchType(b.Settings(guildID).SearchType, previous.Info.Author)
+	if previous.Info.SourceName == "youtube" {
+		identifier = fmt.Sprintf("https://www.youtube.com/watch?v=%s&list=RD%s", previous.Info.Identifier, previous.Info.Identifier)
+	}
+
+	result, err := b.Lavalink.BestNode().LoadTracks(ctx, identifier)
+	if err != nil {
+		log.Error("Failed to load autoplay tracks: ", err)
+		return lavalink.Track{}, false
+	}
+
+	var candidates []lavalink.Track
+	switch data := result.Data.(type) {
+	case lavalink.Playlist:
+		candidates = data.Tracks
+	case lavalink.Search:
+		candidates = data
+	case lavalink.Track:
+		candidates = []lavalink.Track{data}
+	}
+
+	for _, track := range candidates {
+		if track.Info.Identifier != previous.Info.Identifier {
+			return track, true
+		}
+	}
+	return lavalink.Track{}, false
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"os"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -12,15 +14,38 @@ import (
 	"github.com/disgoorg/snowflake/v2"
 
 	bot_config "jukeboxitus/src/bot/config"
+	"jukeboxitus/src/bot/lyrics"
+	"jukeboxitus/src/bot/resolver"
 )
 
 type Bot struct {
-	Session     *discordgo.Session
-	Lavalink    disgolink.Client
-	Handlers    map[string]func(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error
-	Queues      *QueueManager
-	SearchType  bot_config.SearchType
-	GeniusToken string
+	Session       *discordgo.Session
+	Lavalink      disgolink.Client
+	Handlers      map[string]func(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error
+	Autocompletes map[string]func(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error
+	Components    map[string]func(event *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) error
+	Queues        *QueueManager
+
+	GuildSettings *GuildSettingsManager
+	Playlists     *PlaylistStore
+	LyricsCache   *lyrics.Cache
+	History       *History
+	Resolvers     resolver.Resolvers
+
+	configMu   sync.RWMutex
+	config     bot_config.Config
+	configured bool
+
+	idle          idleTimers
+	infoCache     nodeInfoCache
+	searches      searchResults
+	lyricsViews   lyricsViews
+	lyricsMatches lyricsMatches
+	lazyBatches   lazyBatches
+	streams       streamTitles
+	nowPlaying    liveCards
+	liveLyrics    liveCards
+	announcements announcements
 }
 
 var (
@@ -29,7 +54,18 @@ var (
 	GuildId       = os.Getenv("GUILD_ID")
 )
 
-func (b *Bot) OnApplicationCommand(session *discordgo.Session, event *discordgo.InteractionCreate) {
+func (b *Bot) OnInteractionCreate(session *discordgo.Session, event *discordgo.InteractionCreate) {
+	switch event.Type {
+	case discordgo.InteractionApplicationCommand:
+		b.onApplicationCommand(event)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		b.onAutocomplete(event)
+	case discordgo.InteractionMessageComponent:
+		b.onComponent(event)
+	}
+}
+
+func (b *Bot) onApplicationCommand(event *discordgo.InteractionCreate) {
 	data := event.ApplicationCommandData()
 
 	handler, ok := b.Handlers[data.Name]
@@ -37,11 +73,61 @@ func (b *Bot) OnApplicationCommand(session *discordgo.Session, event *discordgo.
 		log.Info("unknown command: ", data.Name)
 		return
 	}
+	if reason, ok := b.checkAccess(event, data.Name); !ok {
+		if err := b.SendResponse(event.Interaction, "Permission Denied", reason, ColorError); err != nil {
+			log.Error("error handling command: ", err)
+		}
+		return
+	}
 	if err := handler(event, data); err != nil {
 		log.Error("error handling command: ", err)
 	}
 }
 
+func (b *Bot) onAutocomplete(event *discordgo.InteractionCreate) {
+	data := event.ApplicationCommandData()
+
+	handler, ok := b.Autocompletes[data.Name]
+	if !ok {
+		log.Info("unknown autocomplete: ", data.Name)
+		return
+	}
+	if err := handler(event, data); err != nil {
+		log.Error("error handling autocomplete: ", err)
+	}
+}
+
+// onComponent routes buttons and select menus by the part of their custom ID
+// before the first ':', the rest is left for the handler to parse.
+func (b *Bot) onComponent(event *discordgo.InteractionCreate) {
+	data := event.MessageComponentData()
+
+	prefix, _, _ := strings.Cut(data.CustomID, ":")
+	handler, ok := b.Components[prefix]
+	if !ok {
+		log.Info("unknown component: ", data.CustomID)
+		return
+	}
+	if reason, ok := b.checkAccess(event, componentCommands[prefix]); !ok {
+		if err := b.SendResponse(event.Interaction, "Permission Denied", reason, ColorError); err != nil {
+			log.Error("error handling component: ", err)
+		}
+		return
+	}
+	if err := handler(event, data); err != nil {
+		log.Error("error handling component: ", err)
+	}
+}
+
+// componentCommands maps the components to the command that sent them, whose
+// access settings apply to them as well.
+var componentCommands = map[string]string{
+	"search":     "search",
+	"queue":      "queue",
+	"lyrics":     "lyrics",
+	"lyricspick": "lyrics",
+}
+
 func (b *Bot) OnVoiceStateUpdate(session *discordgo.Session, event *discordgo.VoiceStateUpdate) {
 	if event.UserID != session.State.User.ID {
 		return
@@ -56,6 +142,15 @@ func (b *Bot) OnVoiceStateUpdate(session *discordgo.Session, event *discordgo.Vo
 	b.Lavalink.OnVoiceStateUpdate(context.Background(), snowflake.MustParse(event.GuildID), channelID, event.SessionID)
 	if event.ChannelID == "" {
 		b.Queues.Delete(event.GuildID)
+		b.stopIdleTimer(event.GuildID)
+		b.stopStreamTitle(event.GuildID)
+		b.nowPlaying.stop(event.GuildID)
+		b.liveLyrics.stop(event.GuildID)
+		b.lazyBatches.stop(event.GuildID)
+		b.forgetSession(event.GuildID)
+		if err := b.History.Leave(event.GuildID, time.Now()); err != nil {
+			log.Error("failed to record the play: ", err)
+		}
 	}
 }
 
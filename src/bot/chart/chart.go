@@ -0,0 +1,161 @@
+// Package chart draws simple bar charts as PNG images with the standard
+// library, labelled with a small built-in pixel font.
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+)
+
+const (
+	marginLeft   = 56
+	marginRight  = 16
+	marginTop    = 16
+	marginBottom = 32
+	// gridLines is about how many horizontal lines divide the chart.
+	gridLines = 4
+)
+
+// Bar is a bar of a chart. Labels are drawn under the bars and may only hold
+// digits, '.', ':' and 'h'; empty labels leave the bar unlabelled.
+type Bar struct {
+	Value float64
+	Label string
+}
+
+// Bars is a bar chart. Unit is drawn after the values on the vertical axis.
+type Bars struct {
+	Width  int
+	Height int
+	Bars   []Bar
+	Unit   string
+
+	Background color.Color
+	Grid       color.Color
+	Fill       color.Color
+	Text       color.Color
+}
+
+// PNG draws the chart and encodes it as a PNG image.
+func (c Bars) PNG(w io.Writer) error {
+	return png.Encode(w, c.Draw())
+}
+
+// Draw draws the chart.
+func (c Bars) Draw() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, c.Width, c.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c.Background), image.Point{}, draw.Src)
+
+	plot := image.Rect(marginLeft, marginTop, c.Width-marginRight, c.Height-marginBottom)
+	if plot.Dx() <= 0 || plot.Dy() <= 0 {
+		return img
+	}
+
+	maxValue := 0.0
+	for _, bar := range c.Bars {
+		maxValue = max(maxValue, bar.Value)
+	}
+	step := niceStep(maxValue / gridLines)
+	top := math.Max(step, math.Ceil(maxValue/step)*step)
+	y := func(value float64) int {
+		return plot.Max.Y - int(math.Round(value/top*float64(plot.Dy())))
+	}
+
+	// Grid lines with their value on the left
+	for i := 0; float64(i)*step <= top+step/2; i++ {
+		// Rounded, since steps like 0.1 add up to values like 0.30000000000000004
+		value := math.Round(float64(i)*step*1000) / 1000
+		line := y(value)
+		fill(img, image.Rect(plot.Min.X, line, plot.Max.X, line+1), c.Grid)
+		label := strconv.FormatFloat(value, 'f', -1, 64) + c.Unit
+		text(img, label, plot.Min.X-8-textWidth(label), line-glyphHeight*scale/2, c.Text)
+	}
+
+	if len(c.Bars) == 0 {
+		return img
+	}
+	slot := float64(plot.Dx()) / float64(len(c.Bars))
+	width := max(1, int(slot*0.7))
+	for i, bar := range c.Bars {
+		center := plot.Min.X + int(slot*(float64(i)+0.5))
+		left := center - width/2
+		if bar.Value > 0 {
+			fill(img, image.Rect(left, y(bar.Value), left+width, plot.Max.Y), c.Fill)
+		}
+		if bar.Label != "" {
+			text(img, bar.Label, center-textWidth(bar.Label)/2, plot.Max.Y+8, c.Text)
+		}
+	}
+	return img
+}
+
+// niceStep rounds a grid step up to 1, 2 or 5 times a power of ten.
+func niceStep(step float64) float64 {
+	if step <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(step)))
+	for _, factor := range []float64{1, 2, 5} {
+		if factor*magnitude >= step {
+			return factor * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// The pixel font has 3×5 glyphs, drawn at scale with a pixel between them.
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+	scale       = 2
+)
+
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'.': {"...", "...", "...", "...", ".#."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'h': {"#..", "#..", "###", "#.#", "#.#"},
+}
+
+func textWidth(s string) int {
+	n := len([]rune(s))
+	if n == 0 {
+		return 0
+	}
+	return n*(glyphWidth+1)*scale - scale
+}
+
+// text draws s with its top left corner at x, y. Unknown characters are left
+// blank.
+func text(img *image.RGBA, s string, x int, y int, c color.Color) {
+	for _, r := range s {
+		glyph := glyphs[r]
+		for row, line := range glyph {
+			for col, pixel := range line {
+				if pixel == '#' {
+					px, py := x+col*scale, y+row*scale
+					fill(img, image.Rect(px, py, px+scale, py+scale), c)
+				}
+			}
+		}
+		x += (glyphWidth + 1) * scale
+	}
+}
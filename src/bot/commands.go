@@ -4,8 +4,66 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/disgoorg/json"
 	"github.com/disgoorg/log"
+
+	bot_config "jukeboxitus/src/bot/config"
 )
 
+// sourceOption overrides the guild's search source for a single request. Its
+// choices come from autocomplete since they depend on the node's plugins.
+var sourceOption = &discordgo.ApplicationCommandOption{
+	Type:         discordgo.ApplicationCommandOptionString,
+	Name:         "source",
+	Description:  "Where to search, defaults to the server's search source",
+	Autocomplete: true,
+}
+
+// Options choosing which part of a playlist gets queued.
+var (
+	playlistStartOption = &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionInteger,
+		Name:        "start",
+		Description: "First playlist track to queue, counting from 1",
+		MinValue:    json.Ptr(1.0),
+	}
+	playlistEndOption = &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionInteger,
+		Name:        "end",
+		Description: "Last playlist track to queue",
+		MinValue:    json.Ptr(1.0),
+	}
+	playlistShuffleOption = &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionBoolean,
+		Name:        "shuffle",
+		Description: "Shuffle the playlist while adding it",
+	}
+)
+
+// Options shared by the /playlist subcommands.
+var (
+	playlistNameOption = &discordgo.ApplicationCommandOption{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         "name",
+		Description:  "The playlist's name",
+		Required:     true,
+		MaxLength:    50,
+		Autocomplete: true,
+	}
+	playlistScopeOption = &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "scope",
+		Description: "Your own playlists or the server's, defaults to yours",
+		Choices:     stringChoices("user", "server"),
+	}
+)
+
+// statsRangeOption limits /stats to a recent time range.
+var statsRangeOption = &discordgo.ApplicationCommandOption{
+	Type:        discordgo.ApplicationCommandOptionString,
+	Name:        "range",
+	Description: "How far back to look, all time by default",
+	Choices:     stringChoices("day", "week", "month", "year", "all"),
+}
+
 var commands = []*discordgo.ApplicationCommand{
 	{
 		Name:        "play",
@@ -15,8 +73,164 @@ var commands = []*discordgo.ApplicationCommand{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "identifier",
 				Description: "The song link or search query",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Name:        "attachment",
+				Description: "An audio or video file to play instead",
+			},
+			sourceOption,
+			playlistStartOption,
+			playlistEndOption,
+			playlistShuffleOption,
+		},
+	},
+	{
+		Name:        "playnext",
+		Description: "Plays a song after the current one",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "identifier",
+				Description: "The song link or search query",
+				Required:    true,
+			},
+			sourceOption,
+			playlistStartOption,
+			playlistEndOption,
+			playlistShuffleOption,
+		},
+	},
+	{
+		Name:        "playnow",
+		Description: "Interrupts the current song to play another one",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "identifier",
+				Description: "The song link or search query",
+				Required:    true,
+			},
+			sourceOption,
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "resume",
+				Description: "Resume the interrupted song afterwards, defaults to true",
+			},
+			playlistStartOption,
+			playlistEndOption,
+			playlistShuffleOption,
+		},
+	},
+	{
+		Name: "Play in voice",
+		Type: discordgo.MessageApplicationCommand,
+	},
+	{
+		Name: "Add to queue",
+		Type: discordgo.MessageApplicationCommand,
+	},
+	{
+		Name:        "radio",
+		Description: "Plays an internet radio station",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "station",
+				Description:  "The station to play, leave empty to list them",
+				Autocomplete: true,
+			},
+		},
+	},
+	{
+		Name:        "playlist",
+		Description: "Manages saved playlists",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "create",
+				Description: "Creates an empty playlist",
+				Options:     []*discordgo.ApplicationCommandOption{playlistNameOption, playlistScopeOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Adds songs to a playlist, the current song by default",
+				Options: []*discordgo.ApplicationCommandOption{
+					playlistNameOption,
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "identifier",
+						Description: "The song link or search query",
+					},
+					playlistScopeOption,
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Removes a song from a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					playlistNameOption,
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "position",
+						Description: "Position of the song in the playlist",
+						Required:    true,
+						MinValue:    json.Ptr(1.0),
+					},
+					playlistScopeOption,
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "Lists the saved playlists",
+				Options:     []*discordgo.ApplicationCommandOption{playlistScopeOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "show",
+				Description: "Shows the songs of a playlist",
+				Options:     []*discordgo.ApplicationCommandOption{playlistNameOption, playlistScopeOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "play",
+				Description: "Adds a playlist to the queue",
+				Options: []*discordgo.ApplicationCommandOption{
+					playlistNameOption,
+					playlistScopeOption,
+					playlistStartOption,
+					playlistEndOption,
+					playlistShuffleOption,
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "delete",
+				Description: "Deletes a playlist",
+				Options:     []*discordgo.ApplicationCommandOption{playlistNameOption, playlistScopeOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "save-queue",
+				Description: "Saves the current song and the queue as a playlist",
+				Options:     []*discordgo.ApplicationCommandOption{playlistNameOption, playlistScopeOption},
+			},
+		},
+	},
+	{
+		Name:        "search",
+		Description: "Searches for a song and lets you pick the result to play",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "query",
+				Description: "What to search for",
 				Required:    true,
 			},
+			sourceOption,
 		},
 	},
 	{
@@ -30,6 +244,13 @@ var commands = []*discordgo.ApplicationCommand{
 	{
 		Name:        "now-playing",
 		Description: "Shows the current playing song",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "persistent",
+				Description: "Keep the card updated while the song plays",
+			},
+		},
 	},
 	{
 		Name:        "stop",
@@ -45,7 +266,40 @@ var commands = []*discordgo.ApplicationCommand{
 	},
 	{
 		Name:        "queue",
-		Description: "Shows the current queue",
+		Description: "Shows, exports or imports the queue",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "Shows the current queue",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "export",
+				Description: "Sends the current song and the queue as a file",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "format",
+						Description: "The file format, M3U by default",
+						Choices:     stringChoices("m3u", "json", "txt"),
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "import",
+				Description: "Adds the songs of an M3U, JSON or text file to the queue",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionAttachment,
+						Name:        "file",
+						Description: "A file exported with /queue export, or one link or search per line",
+						Required:    true,
+					},
+				},
+			},
+		},
 	},
 	{
 		Name:        "clear-queue",
@@ -115,8 +369,175 @@ var commands = []*discordgo.ApplicationCommand{
 				Description: "The name of the song",
 				Required:    false,
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "live",
+				Description: "Follow the current song with synced lyrics",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "position",
+				Description: "Get the lyrics of the song at this position in the queue",
+				MinValue:    json.Ptr(1.0),
+			},
+		},
+	},
+	{
+		Name:        "stats",
+		Description: "Shows what was played in this server",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "top-tracks",
+				Description: "Shows the most played tracks",
+				Options:     []*discordgo.ApplicationCommandOption{statsRangeOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "top-artists",
+				Description: "Shows the most played artists",
+				Options:     []*discordgo.ApplicationCommandOption{statsRangeOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "top-requesters",
+				Description: "Shows who requested the most tracks",
+				Options:     []*discordgo.ApplicationCommandOption{statsRangeOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "me",
+				Description: "Shows what you requested",
+				Options:     []*discordgo.ApplicationCommandOption{statsRangeOption},
+			},
+		},
+	},
+	{
+		Name:        "recap",
+		Description: "Sums up what this server listened to this month or year",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "period",
+				Description: "The period to sum up, this month by default",
+				Choices:     stringChoices("month", "year"),
+			},
 		},
 	},
+	{
+		Name:                     "settings",
+		Description:              "Shows or changes the settings of this server",
+		DefaultMemberPermissions: json.Ptr(int64(discordgo.PermissionManageServer)),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "Shows the current settings",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "set",
+				Description: "Changes one or more settings",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "search-source",
+						Description: "Where plain search queries are looked up",
+						Choices:     searchTypeChoices(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "default-volume",
+						Description: "Volume of a newly started player (0-100)",
+						MinValue:    json.Ptr(0.0),
+						MaxValue:    100,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionRole,
+						Name:        "dj-role",
+						Description: "Role required to control playback",
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "music-channel",
+						Description:  "The only text channel music commands can be used in",
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "idle-timeout",
+						Description: "Minutes to wait before leaving when nothing is playing (0 = never)",
+						MinValue:    json.Ptr(0.0),
+						MaxValue:    1440,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "autoplay",
+						Description: "Keep playing related tracks when the queue ends",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "max-queue-length",
+						Description: "Maximum number of queued tracks (0 = unlimited)",
+						MinValue:    json.Ptr(0.0),
+						MaxValue:    10000,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "announce-now-playing",
+						Description: "Post every new track in the music channel, or where playback was started",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "delete-old-announcements",
+						Description: "Delete the previous now-playing post when a new track starts",
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "reset",
+				Description: "Resets one setting, or all of them",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "setting",
+						Description: "The setting to reset, all of them if omitted",
+						Choices:     stringChoices(guildSettingKeys...),
+					},
+				},
+			},
+		},
+	},
+}
+
+// guildSettingKeys are the option names of /settings set.
+var guildSettingKeys = []string{
+	"search-source", "default-volume", "dj-role", "music-channel",
+	"idle-timeout", "autoplay", "max-queue-length", "announce-now-playing",
+	"delete-old-announcements",
+}
+
+func searchTypeChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, searchType := range bot_config.SearchTypes() {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  searchType.String(),
+			Value: searchType.String(),
+		})
+	}
+	return choices
+}
+
+func stringChoices(values ...string) []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(values))
+	for _, value := range values {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  value,
+			Value: value,
+		})
+	}
+	return choices
 }
 
 func RegisterCommands(s *discordgo.Session) {
@@ -1,5 +1,10 @@
 package bot_config
 
+import (
+	"strings"
+	"time"
+)
+
 type LavalinkConfig struct {
 	Name       string `yaml:"Name"`
 	Hostname   string `yaml:"Hostname"`
@@ -9,8 +14,162 @@ type LavalinkConfig struct {
 	SearchType string `yaml:"SearchType"`
 }
 
+type TimeoutsConfig struct {
+	// LoadTracks bounds how long a track or playlist lookup may take.
+	LoadTracks time.Duration `yaml:"LoadTracks"`
+	// Idle is how long the bot stays in a voice channel with nothing to play,
+	// zero means forever. Guilds can override it with /settings.
+	Idle time.Duration `yaml:"Idle"`
+}
+
+type PlaylistsConfig struct {
+	// LazyThreshold is the playlist size above which tracks are queued as
+	// placeholders that are only loaded shortly before they play. Negative
+	// disables lazy loading.
+	LazyThreshold int `yaml:"LazyThreshold"`
+	// MaxTracks is the most tracks queued from a single playlist.
+	MaxTracks int `yaml:"MaxTracks"`
+}
+
+type NowPlayingConfig struct {
+	// UpdateInterval is how often a persistent /now-playing card is edited.
+	UpdateInterval time.Duration `yaml:"UpdateInterval"`
+}
+
+type LyricsConfig struct {
+	// Providers lists the lyrics providers to try, in order. See
+	// LyricsProviderNames.
+	Providers []string `yaml:"Providers"`
+	// CacheSize is how many songs the lyrics cache holds, negative disables
+	// it.
+	CacheSize int `yaml:"CacheSize"`
+	// NotFoundTTL is how long a song without lyrics is not looked up again.
+	NotFoundTTL time.Duration `yaml:"NotFoundTTL"`
+}
+
+// LyricsProviderNames are the lyrics providers the bot knows, in their
+// default order.
+var LyricsProviderNames = []string{"genius", "lrclib", "lavalyrics"}
+
+// RadioStation is an internet radio stream offered by /radio.
+type RadioStation struct {
+	Name string `yaml:"Name"`
+	URL  string `yaml:"URL"`
+}
+
 type Config struct {
-	Token       string         `yaml:"Token"`
-	GeniusToken string         `yaml:"GeniusToken"`
-	Lavalink    LavalinkConfig `yaml:"Lavalink"`
+	Token         string           `yaml:"Token"`
+	GeniusToken   string           `yaml:"GeniusToken"`
+	DefaultVolume int              `yaml:"DefaultVolume"`
+	DataDir       string           `yaml:"DataDir"`
+	Timeouts      TimeoutsConfig   `yaml:"Timeouts"`
+	Playlists     PlaylistsConfig  `yaml:"Playlists"`
+	NowPlaying    NowPlayingConfig `yaml:"NowPlaying"`
+	Lyrics        LyricsConfig     `yaml:"Lyrics"`
+	Lavalink      LavalinkConfig   `yaml:"Lavalink"`
+	Nodes         []LavalinkConfig `yaml:"Nodes"`
+	Radio         []RadioStation   `yaml:"Radio"`
+}
+
+const (
+	defaultLoadTracksTimeout = 10 * time.Second
+	defaultDataDir           = "data"
+	defaultLazyThreshold     = 100
+	defaultMaxPlaylistTracks = 1000
+
+	defaultLyricsCacheSize   = 500
+	defaultLyricsNotFoundTTL = 6 * time.Hour
+
+	defaultNowPlayingUpdate = 10 * time.Second
+	// minNowPlayingUpdate keeps persistent cards well within Discord's rate
+	// limits for message edits.
+	minNowPlayingUpdate = 5 * time.Second
+)
+
+// AllNodes returns the main Lavalink node followed by the additional ones.
+func (c Config) AllNodes() []LavalinkConfig {
+	return append([]LavalinkConfig{c.Lavalink}, c.Nodes...)
+}
+
+func (c Config) SearchType() SearchType {
+	return ParseSearchType(c.Lavalink.SearchType)
+}
+
+func (c Config) LoadTracksTimeout() time.Duration {
+	if c.Timeouts.LoadTracks <= 0 {
+		return defaultLoadTracksTimeout
+	}
+	return c.Timeouts.LoadTracks
+}
+
+// DataPath returns the directory where the bot keeps its persistent state.
+func (c Config) DataPath() string {
+	if c.DataDir == "" {
+		return defaultDataDir
+	}
+	return c.DataDir
+}
+
+// LazyThreshold returns the playlist size above which tracks are loaded
+// lazily, or a negative number when lazy loading is disabled.
+func (c Config) LazyThreshold() int {
+	if c.Playlists.LazyThreshold == 0 {
+		return defaultLazyThreshold
+	}
+	return c.Playlists.LazyThreshold
+}
+
+// MaxPlaylistTracks returns the most tracks queued from a single playlist.
+func (c Config) MaxPlaylistTracks() int {
+	if c.Playlists.MaxTracks <= 0 {
+		return defaultMaxPlaylistTracks
+	}
+	return c.Playlists.MaxTracks
+}
+
+// NowPlayingUpdateInterval returns how often a persistent /now-playing card
+// is edited, never less than minNowPlayingUpdate.
+func (c Config) NowPlayingUpdateInterval() time.Duration {
+	if c.NowPlaying.UpdateInterval <= 0 {
+		return defaultNowPlayingUpdate
+	}
+	return max(c.NowPlaying.UpdateInterval, minNowPlayingUpdate)
+}
+
+// LyricsProviders returns the lyrics providers to try, in order, lowercased.
+func (c Config) LyricsProviders() []string {
+	if len(c.Lyrics.Providers) == 0 {
+		return LyricsProviderNames
+	}
+	providers := make([]string, len(c.Lyrics.Providers))
+	for i, name := range c.Lyrics.Providers {
+		providers[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+	return providers
+}
+
+// LyricsCacheSize returns how many songs the lyrics cache holds, zero when it
+// is disabled.
+func (c Config) LyricsCacheSize() int {
+	if c.Lyrics.CacheSize == 0 {
+		return defaultLyricsCacheSize
+	}
+	return max(c.Lyrics.CacheSize, 0)
+}
+
+func (c Config) LyricsNotFoundTTL() time.Duration {
+	if c.Lyrics.NotFoundTTL <= 0 {
+		return defaultLyricsNotFoundTTL
+	}
+	return c.Lyrics.NotFoundTTL
+}
+
+// Station looks up a radio station by name, ignoring case.
+func (c Config) Station(name string) (RadioStation, bool) {
+	for _, station := range c.Radio {
+		if strings.EqualFold(station.Name, name) {
+			return station, true
+		}
+	}
+	return RadioStation{}, false
 }
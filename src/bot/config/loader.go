@@ -0,0 +1,127 @@
+package bot_config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Source string
+
+const (
+	SourceDefault  Source = "default"
+	SourceEmbedded Source = "embedded"
+	SourceFile     Source = "file"
+	SourceEnv      Source = "env"
+)
+
+// Sources records where every setting of a Config was taken from, keyed by
+// its yaml path (e.g. "Lavalink.Port").
+type Sources map[string]Source
+
+func (s Sources) Of(key string) Source {
+	if src, ok := s[key]; ok {
+		return src
+	}
+	return SourceDefault
+}
+
+type envOverride struct {
+	env   string
+	key   string
+	apply func(c *Config, value string) error
+}
+
+// envOverrides keeps the environment variable names the bot has always used.
+var envOverrides = []envOverride{
+	{"TOKEN", "Token", func(c *Config, v string) error { c.Token = v; return nil }},
+	{"GENIUS_TOKEN", "GeniusToken", func(c *Config, v string) error { c.GeniusToken = v; return nil }},
+	{"NAME", "Lavalink.Name", func(c *Config, v string) error { c.Lavalink.Name = v; return nil }},
+	{"HOSTNAME", "Lavalink.Hostname", func(c *Config, v string) error { c.Lavalink.Hostname = v; return nil }},
+	{"PORT", "Lavalink.Port", func(c *Config, v string) error {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid PORT value: %w", err)
+		}
+		c.Lavalink.Port = port
+		return nil
+	}},
+	{"PASSWORD", "Lavalink.Password", func(c *Config, v string) error { c.Lavalink.Password = v; return nil }},
+	{"SECURED", "Lavalink.Secured", func(c *Config, v string) error {
+		secured, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid SECURED value: %w", err)
+		}
+		c.Lavalink.Secured = secured
+		return nil
+	}},
+	{"SEARCH_TYPE", "Lavalink.SearchType", func(c *Config, v string) error { c.Lavalink.SearchType = v; return nil }},
+	{"DATA_DIR", "DataDir", func(c *Config, v string) error { c.DataDir = v; return nil }},
+}
+
+// Load builds a Config from the embedded config, the config file on disk and
+// the environment, each one overriding the previous. A missing file is not an
+// error, since every setting can also be provided through the environment.
+func Load(embedded []byte, filePath string) (Config, Sources, error) {
+	var config Config
+	sources := Sources{}
+
+	if len(embedded) > 0 {
+		if err := applyYAML(&config, sources, embedded, SourceEmbedded); err != nil {
+			return config, sources, fmt.Errorf("embedded config: %w", err)
+		}
+	}
+
+	if filePath != "" {
+		data, err := os.ReadFile(filePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return config, sources, err
+		}
+		if err == nil {
+			if err := applyYAML(&config, sources, data, SourceFile); err != nil {
+				return config, sources, fmt.Errorf("%s: %w", filePath, err)
+			}
+		}
+	}
+
+	for _, override := range envOverrides {
+		value, ok := os.LookupEnv(override.env)
+		if !ok {
+			continue
+		}
+		if err := override.apply(&config, value); err != nil {
+			return config, sources, err
+		}
+		sources[override.key] = SourceEnv
+	}
+
+	return config, sources, nil
+}
+
+// applyYAML decodes data on top of config, so keys missing from data keep
+// the value of the previous layer, and marks every key it contains.
+func applyYAML(config *Config, sources Sources, data []byte, source Source) error {
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return err
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	markSources(raw, "", source, sources)
+	return nil
+}
+
+func markSources(raw map[string]any, prefix string, source Source, sources Sources) {
+	for key, value := range raw {
+		if nested, ok := value.(map[string]any); ok {
+			markSources(nested, prefix+key+".", source, sources)
+			continue
+		}
+		sources[prefix+key] = source
+	}
+}
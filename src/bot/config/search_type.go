@@ -8,34 +8,89 @@ const (
 	YouTube SearchType = iota
 	YouTubeMusic
 	SoundCloud
+	// The following sources need a Lavalink plugin such as LavaSrc
+	Spotify
+	Deezer
+	AppleMusic
+	YandexMusic
+	Bandcamp
 )
 
 const defaultSearchType = YouTube
 
-var stringToSearchType = map[string]SearchType{
-	"youTube":      YouTube,
-	"youTubeMusic": YouTubeMusic,
-	"soundCloud":   SoundCloud,
+type searchTypeInfo struct {
+	name string
+	// prefix is the Lavalink search prefix, without the trailing ':'
+	prefix string
+	// sourceManager is the name the node reports in /v4/info when the
+	// source is available
+	sourceManager string
+	plugin        bool
 }
 
-var searchTypeToString = map[SearchType]string{
-	YouTube:      "youTube",
-	YouTubeMusic: "youTubeMusic",
-	SoundCloud:   "soundCloud",
+var searchTypes = map[SearchType]searchTypeInfo{
+	YouTube:      {"youTube", "ytsearch", "youtube", false},
+	YouTubeMusic: {"youTubeMusic", "ytmsearch", "youtube", false},
+	SoundCloud:   {"soundCloud", "scsearch", "soundcloud", false},
+	Spotify:      {"spotify", "spsearch", "spotify", true},
+	Deezer:       {"deezer", "dzsearch", "deezer", true},
+	AppleMusic:   {"appleMusic", "amsearch", "applemusic", true},
+	YandexMusic:  {"yandexMusic", "ymsearch", "yandexmusic", true},
+	Bandcamp:     {"bandcamp", "bcsearch", "bandcamp", true},
+}
+
+// SearchTypes lists every known search type in declaration order.
+func SearchTypes() []SearchType {
+	return []SearchType{YouTube, YouTubeMusic, SoundCloud, Spotify, Deezer, AppleMusic, YandexMusic, Bandcamp}
 }
 
 func (s SearchType) String() string {
-	if str, ok := searchTypeToString[s]; ok {
-		return str
+	if info, ok := searchTypes[s]; ok {
+		return info.name
 	}
-	return searchTypeToString[YouTube]
+	return searchTypes[YouTube].name
 }
 
-func ParseSearchType(str string) SearchType {
-	str = strings.TrimSpace(str)
+// Prefix returns the Lavalink search prefix, e.g. "spsearch".
+func (s SearchType) Prefix() string {
+	if info, ok := searchTypes[s]; ok {
+		return info.prefix
+	}
+	return searchTypes[YouTube].prefix
+}
+
+// SourceManager returns the source manager the node must report for this
+// search type to work.
+func (s SearchType) SourceManager() string {
+	return searchTypes[s].sourceManager
+}
 
-	if val, ok := stringToSearchType[str]; ok {
+// IsPlugin reports whether the search type needs a Lavalink plugin.
+func (s SearchType) IsPlugin() bool {
+	return searchTypes[s].plugin
+}
+
+func ParseSearchType(str string) SearchType {
+	if val, ok := LookupSearchType(str); ok {
 		return val
 	}
 	return defaultSearchType
 }
+
+// LookupSearchType is like ParseSearchType but reports whether str named a
+// known search type instead of falling back to the default. Both the name
+// ("spotify") and the Lavalink prefix ("spsearch", "spsearch:") are accepted.
+func LookupSearchType(str string) (SearchType, bool) {
+	str = strings.TrimSuffix(strings.TrimSpace(str), ":")
+	if str == "" {
+		return defaultSearchType, true
+	}
+
+	for _, searchType := range SearchTypes() {
+		info := searchTypes[searchType]
+		if strings.EqualFold(str, info.name) || strings.EqualFold(str, info.prefix) {
+			return searchType, true
+		}
+	}
+	return defaultSearchType, false
+}
@@ -0,0 +1,186 @@
+package bot_config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+type Problem struct {
+	Key      string
+	Severity Severity
+	Message  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Severity, p.Key, p.Message)
+}
+
+// Validate checks the settings that can be verified without network access.
+func (c Config) Validate() []Problem {
+	var problems []Problem
+	add := func(key string, severity Severity, format string, args ...any) {
+		problems = append(problems, Problem{Key: key, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.Token == "" {
+		add("Token", SeverityError, "missing 'TOKEN'")
+	} else if err := checkTokenFormat(c.Token); err != nil {
+		add("Token", SeverityError, "%s", err)
+	}
+
+	if c.GeniusToken == "" && slices.Contains(c.LyricsProviders(), "genius") {
+		add("GeniusToken", SeverityWarning, "missing 'GENIUS_TOKEN', Genius lyrics will not be available")
+	}
+
+	if c.DefaultVolume < 0 || c.DefaultVolume > 100 {
+		add("DefaultVolume", SeverityError, "volume %d is out of range (0-100)", c.DefaultVolume)
+	}
+	if c.Timeouts.LoadTracks < 0 {
+		add("Timeouts.LoadTracks", SeverityError, "timeout must not be negative")
+	}
+	if c.Timeouts.Idle < 0 {
+		add("Timeouts.Idle", SeverityError, "timeout must not be negative")
+	}
+	if c.Playlists.MaxTracks < 0 {
+		add("Playlists.MaxTracks", SeverityError, "must not be negative")
+	}
+	for i, name := range c.LyricsProviders() {
+		if !slices.Contains(LyricsProviderNames, name) {
+			add(fmt.Sprintf("Lyrics.Providers[%d]", i), SeverityError, "unknown provider %q (known: %s)",
+				name, strings.Join(LyricsProviderNames, ", "))
+		}
+	}
+	if c.Lyrics.NotFoundTTL < 0 {
+		add("Lyrics.NotFoundTTL", SeverityError, "duration must not be negative")
+	}
+	if c.NowPlaying.UpdateInterval < 0 {
+		add("NowPlaying.UpdateInterval", SeverityError, "interval must not be negative")
+	} else if c.NowPlaying.UpdateInterval > 0 && c.NowPlaying.UpdateInterval < minNowPlayingUpdate {
+		add("NowPlaying.UpdateInterval", SeverityWarning, "interval is raised to %s to respect Discord rate limits", minNowPlayingUpdate)
+	}
+
+	if c.Lavalink.Hostname == "" {
+		add("Lavalink.Hostname", SeverityError, "missing 'HOSTNAME'")
+	}
+	if c.Lavalink.Port < 1 || c.Lavalink.Port > 65535 {
+		add("Lavalink.Port", SeverityError, "port %d is out of range (1-65535)", c.Lavalink.Port)
+	}
+	if c.Lavalink.Password == "" {
+		add("Lavalink.Password", SeverityError, "missing 'PASSWORD'")
+	}
+	if _, ok := LookupSearchType(c.Lavalink.SearchType); !ok {
+		add("Lavalink.SearchType", SeverityError, "unknown search type %q", c.Lavalink.SearchType)
+	}
+
+	names := map[string]bool{c.Lavalink.Name: true}
+	for i, node := range c.Nodes {
+		key := fmt.Sprintf("Nodes[%d]", i)
+		if names[node.Name] {
+			add(key, SeverityError, "duplicate node name %q", node.Name)
+		}
+		names[node.Name] = true
+		if node.Hostname == "" {
+			add(key, SeverityError, "missing Hostname")
+		}
+		if node.Port < 1 || node.Port > 65535 {
+			add(key, SeverityError, "port %d is out of range (1-65535)", node.Port)
+		}
+		if node.Password == "" {
+			add(key, SeverityError, "missing Password")
+		}
+	}
+
+	stations := map[string]bool{}
+	for i, station := range c.Radio {
+		key := fmt.Sprintf("Radio[%d]", i)
+		if station.Name == "" {
+			add(key, SeverityError, "missing Name")
+		} else if stations[strings.ToLower(station.Name)] {
+			add(key, SeverityError, "duplicate station name %q", station.Name)
+		}
+		stations[strings.ToLower(station.Name)] = true
+		if !strings.HasPrefix(station.URL, "http://") && !strings.HasPrefix(station.URL, "https://") {
+			add(key, SeverityError, "URL %q must start with http:// or https://", station.URL)
+		}
+	}
+
+	return problems
+}
+
+// checkTokenFormat verifies the shape of a Discord bot token: three dot
+// separated base64url segments, the first one being the encoded bot user ID.
+func checkTokenFormat(token string) error {
+	token = strings.TrimPrefix(token, "Bot ")
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("token must have 3 dot separated segments, found %d", len(parts))
+	}
+	for _, part := range parts {
+		if part == "" || strings.Trim(part, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_") != "" {
+			return fmt.Errorf("token contains invalid characters")
+		}
+	}
+
+	id, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[0], "="))
+	if err != nil || strings.Trim(string(id), "0123456789") != "" {
+		return fmt.Errorf("token does not start with an encoded user ID")
+	}
+	return nil
+}
+
+// Redact hides a secret while keeping enough of it to tell two values apart.
+func Redact(secret string) string {
+	switch {
+	case secret == "":
+		return "(unset)"
+	case len(secret) < 12:
+		return "****"
+	default:
+		return "****" + secret[len(secret)-4:]
+	}
+}
+
+// WriteSummary prints every setting along with the source it came from.
+// Secrets are always redacted.
+func (c Config) WriteSummary(w io.Writer, sources Sources) {
+	fmt.Fprintf(w, "Token (%s): %s\n", sources.Of("Token"), Redact(c.Token))
+	fmt.Fprintf(w, "GeniusToken (%s): %s\n", sources.Of("GeniusToken"), Redact(c.GeniusToken))
+	fmt.Fprintf(w, "Lavalink:\n")
+	fmt.Fprintf(w, "	Name (%s): %q\n", sources.Of("Lavalink.Name"), c.Lavalink.Name)
+	fmt.Fprintf(w, "	Hostname (%s): %q\n", sources.Of("Lavalink.Hostname"), c.Lavalink.Hostname)
+	fmt.Fprintf(w, "	Port (%s): %d\n", sources.Of("Lavalink.Port"), c.Lavalink.Port)
+	fmt.Fprintf(w, "	Password (%s): %s\n", sources.Of("Lavalink.Password"), Redact(c.Lavalink.Password))
+	fmt.Fprintf(w, "	Secured (%s): %v\n", sources.Of("Lavalink.Secured"), c.Lavalink.Secured)
+	fmt.Fprintf(w, "	SearchType (%s): %v\n", sources.Of("Lavalink.SearchType"), c.Lavalink.SearchType)
+	fmt.Fprintf(w, "DefaultVolume (%s): %d\n", sources.Of("DefaultVolume"), c.DefaultVolume)
+	fmt.Fprintf(w, "DataDir (%s): %q\n", sources.Of("DataDir"), c.DataPath())
+	fmt.Fprintf(w, "Timeouts:\n")
+	fmt.Fprintf(w, "	LoadTracks (%s): %s\n", sources.Of("Timeouts.LoadTracks"), c.LoadTracksTimeout())
+	fmt.Fprintf(w, "	Idle (%s): %s\n", sources.Of("Timeouts.Idle"), c.Timeouts.Idle)
+	fmt.Fprintf(w, "Playlists:\n")
+	fmt.Fprintf(w, "	LazyThreshold (%s): %d\n", sources.Of("Playlists.LazyThreshold"), c.LazyThreshold())
+	fmt.Fprintf(w, "	MaxTracks (%s): %d\n", sources.Of("Playlists.MaxTracks"), c.MaxPlaylistTracks())
+	fmt.Fprintf(w, "Lyrics:\n")
+	fmt.Fprintf(w, "	Providers (%s): %s\n", sources.Of("Lyrics.Providers"), strings.Join(c.LyricsProviders(), ", "))
+	fmt.Fprintf(w, "	CacheSize (%s): %d\n", sources.Of("Lyrics.CacheSize"), c.LyricsCacheSize())
+	fmt.Fprintf(w, "	NotFoundTTL (%s): %s\n", sources.Of("Lyrics.NotFoundTTL"), c.LyricsNotFoundTTL())
+	fmt.Fprintf(w, "NowPlaying:\n")
+	fmt.Fprintf(w, "	UpdateInterval (%s): %s\n", sources.Of("NowPlaying.UpdateInterval"), c.NowPlayingUpdateInterval())
+	for i, node := range c.Nodes {
+		fmt.Fprintf(w, "Nodes[%d] (%s): %q %s:%d (password %s, secured %v)\n", i, sources.Of("Nodes"),
+			node.Name, node.Hostname, node.Port, Redact(node.Password), node.Secured)
+	}
+	for i, station := range c.Radio {
+		fmt.Fprintf(w, "Radio[%d] (%s): %q %s\n", i, sources.Of("Radio"), station.Name, station.URL)
+	}
+}
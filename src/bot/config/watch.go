@@ -0,0 +1,46 @@
+package bot_config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Watch polls filePath every interval and calls onChange whenever its
+// modification time or size changes, including when it is created or
+// removed. Polling keeps this working on bind mounts where inotify events
+// are not delivered. Watch blocks until ctx is done.
+func Watch(ctx context.Context, filePath string, interval time.Duration, onChange func()) {
+	last := fileStamp(filePath)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := fileStamp(filePath)
+			if current == last {
+				continue
+			}
+			last = current
+			onChange()
+		}
+	}
+}
+
+type stamp struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+func fileStamp(filePath string) stamp {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return stamp{}
+	}
+	return stamp{modTime: info.ModTime(), size: info.Size(), exists: true}
+}
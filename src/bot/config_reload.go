@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/log"
+
+	bot_config "jukeboxitus/src/bot/config"
+)
+
+// nodeRollbackTimeout is how long restoring the previous nodes may take after
+// a node failed to connect.
+const nodeRollbackTimeout = 10 * time.Second
+
+// Config returns the configuration currently in effect.
+func (b *Bot) Config() bot_config.Config {
+	b.configMu.RLock()
+	defer b.configMu.RUnlock()
+	return b.config
+}
+
+// ApplyConfig makes config the active configuration. Search type, default
+// volume, timeouts and the Genius token are read from it by the handlers,
+// as defaults for guilds without their own settings, and the Lavalink node
+// list is synced right away. Settings that only take effect on restart are
+// returned as warnings and otherwise ignored.
+func (b *Bot) ApplyConfig(ctx context.Context, config bot_config.Config) ([]string, error) {
+	b.configMu.Lock()
+	old := b.config
+	initial := !b.configured
+	b.configMu.Unlock()
+
+	var warnings []string
+	if !initial && config.Token != old.Token {
+		warnings = append(warnings, "Token changed, restart the bot to log in with the new token")
+		config.Token = old.Token
+	}
+	if !initial && config.DataPath() != old.DataPath() {
+		warnings = append(warnings, "DataDir changed, restart the bot to move its data")
+		config.DataDir = old.DataDir
+	}
+	// The lyrics cache is created with its size and TTL on startup
+	if !initial && config.LyricsCacheSize() != old.LyricsCacheSize() {
+		warnings = append(warnings, "Lyrics.CacheSize changed, restart the bot to resize the lyrics cache")
+		config.Lyrics.CacheSize = old.Lyrics.CacheSize
+	}
+	if !initial && config.LyricsNotFoundTTL() != old.LyricsNotFoundTTL() {
+		warnings = append(warnings, "Lyrics.NotFoundTTL changed, restart the bot to apply it to the lyrics cache")
+		config.Lyrics.NotFoundTTL = old.Lyrics.NotFoundTTL
+	}
+
+	if err := b.syncNodes(ctx, old.AllNodes(), config.AllNodes(), initial); err != nil {
+		return warnings, err
+	}
+	b.infoCache.invalidate()
+
+	b.configMu.Lock()
+	b.config = config
+	b.configured = true
+	b.configMu.Unlock()
+
+	return warnings, nil
+}
+
+// syncNodes connects the nodes that were added or changed and disconnects
+// the ones that were removed. On the initial sync every node is added. When
+// a node fails to connect, the nodes are rolled back to oldNodes so the
+// running nodes still match the configuration in effect.
+func (b *Bot) syncNodes(ctx context.Context, oldNodes, newNodes []bot_config.LavalinkConfig, initial bool) error {
+	previous := make(map[string]bot_config.LavalinkConfig, len(oldNodes))
+	if !initial {
+		for _, node := range oldNodes {
+			previous[node.Name] = node
+		}
+	}
+
+	var (
+		added    []string
+		replaced []bot_config.LavalinkConfig
+	)
+	wanted := make(map[string]bool, len(newNodes))
+	for _, node := range newNodes {
+		wanted[node.Name] = true
+		if old, ok := previous[node.Name]; ok && sameNode(old, node) {
+			continue
+		}
+		if old, ok := previous[node.Name]; ok {
+			log.Infof("lavalink node %q changed, reconnecting", node.Name)
+			b.Lavalink.RemoveNode(node.Name)
+			replaced = append(replaced, old)
+		}
+
+		if err := b.addNode(ctx, node); err != nil {
+			b.rollbackNodes(ctx, added, replaced)
+			return fmt.Errorf("failed to add lavalink node %q: %w", node.Name, err)
+		}
+		added = append(added, node.Name)
+		log.Infof("lavalink node %q added", node.Name)
+	}
+
+	for name := range previous {
+		if !wanted[name] {
+			b.Lavalink.RemoveNode(name)
+			log.Infof("lavalink node %q removed", name)
+		}
+	}
+	return nil
+}
+
+// rollbackNodes undoes a failed sync: the nodes added are removed and the
+// ones replaced are connected again with their old settings.
+func (b *Bot) rollbackNodes(ctx context.Context, added []string, replaced []bot_config.LavalinkConfig) {
+	for _, name := range added {
+		b.Lavalink.RemoveNode(name)
+		log.Infof("lavalink node %q removed, the configuration was not applied", name)
+	}
+
+	// The sync may have failed because ctx ran out
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), nodeRollbackTimeout)
+	defer cancel()
+	for _, node := range replaced {
+		if err := b.addNode(ctx, node); err != nil {
+			log.Errorf("failed to restore lavalink node %q: %s", node.Name, err)
+			continue
+		}
+		log.Infof("lavalink node %q restored", node.Name)
+	}
+}
+
+func (b *Bot) addNode(ctx context.Context, node bot_config.LavalinkConfig) error {
+	_, err := b.Lavalink.AddNode(ctx, disgolink.NodeConfig{
+		Name:     node.Name,
+		Address:  fmt.Sprintf("%s:%d", node.Hostname, node.Port),
+		Password: node.Password,
+		Secure:   node.Secured,
+	})
+	return err
+}
+
+// sameNode compares the connection settings of two nodes. The search type is
+// not part of the connection and can change without reconnecting.
+func sameNode(a, b bot_config.LavalinkConfig) bool {
+	return a.Name == b.Name &&
+		a.Hostname == b.Hostname &&
+		a.Port == b.Port &&
+		a.Password == b.Password &&
+		a.Secured == b.Secured
+}
@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	bot_config "jukeboxitus/src/bot/config"
+	"jukeboxitus/src/bot/store"
+)
+
+// GuildSettings holds what a server configured through /settings. Settings
+// that default to a config value are pointers, so a guild that never set them
+// follows the config, including after a reload.
+type GuildSettings struct {
+	SearchType         *string `json:"searchType,omitempty"`
+	DefaultVolume      *int    `json:"defaultVolume,omitempty"`
+	DJRole             string  `json:"djRole,omitempty"`
+	MusicChannel       string  `json:"musicChannel,omitempty"`
+	IdleTimeout        *int    `json:"idleTimeoutSeconds,omitempty"`
+	Autoplay           bool    `json:"autoplay,omitempty"`
+	MaxQueueLength     int     `json:"maxQueueLength,omitempty"`
+	AnnounceNowPlaying bool    `json:"announceNowPlaying,omitempty"`
+	// DeleteAnnouncements removes the previous now-playing announcement
+	// when the next one is posted.
+	DeleteAnnouncements bool `json:"deleteAnnouncements,omitempty"`
+}
+
+// Settings are the effective settings of a guild, with the config defaults
+// filled in.
+type Settings struct {
+	SearchType          bot_config.SearchType
+	DefaultVolume       int
+	DJRole              string
+	MusicChannel        string
+	IdleTimeout         time.Duration
+	Autoplay            bool
+	MaxQueueLength      int
+	AnnounceNowPlaying  bool
+	DeleteAnnouncements bool
+}
+
+func (s GuildSettings) Resolve(config bot_config.Config) Settings {
+	settings := Settings{
+		SearchType:          config.SearchType(),
+		DefaultVolume:       config.DefaultVolume,
+		DJRole:              s.DJRole,
+		MusicChannel:        s.MusicChannel,
+		IdleTimeout:         config.Timeouts.Idle,
+		Autoplay:            s.Autoplay,
+		MaxQueueLength:      s.MaxQueueLength,
+		AnnounceNowPlaying:  s.AnnounceNowPlaying,
+		DeleteAnnouncements: s.DeleteAnnouncements,
+	}
+	if s.SearchType != nil {
+		settings.SearchType = bot_config.ParseSearchType(*s.SearchType)
+	}
+	if s.DefaultVolume != nil {
+		settings.DefaultVolume = *s.DefaultVolume
+	}
+	if s.IdleTimeout != nil {
+		settings.IdleTimeout = time.Duration(*s.IdleTimeout) * time.Second
+	}
+	return settings
+}
+
+type GuildSettingsManager struct {
+	file     *store.JSONFile[map[string]GuildSettings]
+	mu       sync.RWMutex
+	settings map[string]GuildSettings
+}
+
+// NewGuildSettingsManager loads the settings of every guild from dataDir.
+func NewGuildSettingsManager(dataDir string) (*GuildSettingsManager, error) {
+	file := store.NewJSONFile[map[string]GuildSettings](dataDir, "guild_settings.json")
+	settings, err := file.Load()
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = make(map[string]GuildSettings)
+	}
+	return &GuildSettingsManager{file: file, settings: settings}, nil
+}
+
+func (m *GuildSettingsManager) Get(guildID string) GuildSettings {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.settings[guildID]
+}
+
+// All returns a copy of the settings of every guild.
+func (m *GuildSettingsManager) All() map[string]GuildSettings {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return maps.Clone(m.settings)
+}
+
+// Update changes the settings of a guild and persists all of them. The
+// change is only kept once it was saved.
+func (m *GuildSettingsManager) Update(guildID string, update func(settings *GuildSettings)) (GuildSettings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	settings := m.settings[guildID]
+	update(&settings)
+	updated := maps.Clone(m.settings)
+	updated[guildID] = settings
+	if err := m.file.Save(updated); err != nil {
+		return m.settings[guildID], err
+	}
+	m.settings = updated
+	return settings, nil
+}
+
+// Settings returns the effective settings of a guild.
+func (b *Bot) Settings(guildID string) Settings {
+	return b.GuildSettings.Get(guildID).Resolve(b.Config())
+}
@@ -0,0 +1,199 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	bot_config "jukeboxitus/src/bot/config"
+)
+
+// djCommands can only be used by members with the DJ role once a guild has
+// set one. Members who can manage the server are always allowed.
+var djCommands = []string{
+	"playnext", "playnow", "pause", "skip", "stop", "clear-queue", "queue-type", "shuffle", "volume", "bass-boost", "eight-d",
+}
+
+// checkAccess enforces the music channel and DJ role settings. It returns a
+// message explaining why the command was refused.
+func (b *Bot) checkAccess(event *discordgo.InteractionCreate, command string) (string, bool) {
+	if command == "settings" || event.Member == nil {
+		return "", true
+	}
+	settings := b.Settings(event.GuildID)
+
+	if settings.MusicChannel != "" && event.ChannelID != settings.MusicChannel {
+		return fmt.Sprintf("%s Music commands can only be used in <#%s>.", IconError, settings.MusicChannel), false
+	}
+
+	if settings.DJRole != "" && slices.Contains(djCommands, command) &&
+		event.Member.Permissions&discordgo.PermissionManageServer == 0 &&
+		!slices.Contains(event.Member.Roles, settings.DJRole) {
+		return fmt.Sprintf("%s You need the <@&%s> role to use this command.", IconError, settings.DJRole), false
+	}
+
+	return "", true
+}
+
+func (b *Bot) GuildSettingsCommand(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	subcommand := data.Options[0]
+
+	switch subcommand.Name {
+	case "set":
+		return b.setGuildSettings(event, subcommand.Options)
+	case "reset":
+		return b.resetGuildSettings(event, subcommand.Options)
+	default:
+		return b.viewGuildSettings(event)
+	}
+}
+
+func (b *Bot) viewGuildSettings(event *discordgo.InteractionCreate) error {
+	guild := b.GuildSettings.Get(event.GuildID)
+	settings := guild.Resolve(b.Config())
+
+	// source marks settings that still follow the bot config
+	source := func(overridden bool) string {
+		if overridden {
+			return ""
+		}
+		return " *(default)*"
+	}
+	orNone := func(value string, format string) string {
+		if value == "" {
+			return "none"
+		}
+		return fmt.Sprintf(format, value)
+	}
+	orUnlimited := func(value int) string {
+		if value <= 0 {
+			return "unlimited"
+		}
+		return fmt.Sprint(value)
+	}
+	idle := "never"
+	if settings.IdleTimeout > 0 {
+		idle = settings.IdleTimeout.String()
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Search source:** `%s`%s\n", settings.SearchType, source(guild.SearchType != nil))
+	fmt.Fprintf(&sb, "**Default volume:** %d%%%s\n", settings.DefaultVolume, source(guild.DefaultVolume != nil))
+	fmt.Fprintf(&sb, "**DJ role:** %s\n", orNone(settings.DJRole, "<@&%s>"))
+	fmt.Fprintf(&sb, "**Music channel:** %s\n", orNone(settings.MusicChannel, "<#%s>"))
+	fmt.Fprintf(&sb, "**Idle timeout:** %s%s\n", idle, source(guild.IdleTimeout != nil))
+	fmt.Fprintf(&sb, "**Autoplay:** %s\n", onOff(settings.Autoplay))
+	fmt.Fprintf(&sb, "**Max queue length:** %s\n", orUnlimited(settings.MaxQueueLength))
+	fmt.Fprintf(&sb, "**Announce now playing:** %s\n", onOff(settings.AnnounceNowPlaying))
+	fmt.Fprintf(&sb, "**Delete old announcements:** %s\n", onOff(settings.DeleteAnnouncements))
+
+	return b.SendResponse(event.Interaction, fmt.Sprintf("%s Server Settings", IconConfig), sb.String(), ColorDefault)
+}
+
+func (b *Bot) setGuildSettings(event *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	if len(options) == 0 {
+		return b.SendResponse(event.Interaction, "Settings Error",
+			fmt.Sprintf("%s Choose at least one setting to change.", IconError), ColorError)
+	}
+
+	for _, opt := range options {
+		if opt.Name != "search-source" {
+			continue
+		}
+		searchType, ok := bot_config.LookupSearchType(opt.StringValue())
+		if !ok {
+			return b.SendResponse(event.Interaction, "Settings Error",
+				fmt.Sprintf("%s Unknown search source `%s`.", IconError, opt.StringValue()), ColorError)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_, available := b.lookupSource(ctx, searchType.String())
+		cancel()
+		if !available {
+			return b.SendResponse(event.Interaction, "Settings Error",
+				fmt.Sprintf("%s `%s` is not available on the Lavalink node.", IconError, searchType), ColorError)
+		}
+	}
+
+	_, err := b.GuildSettings.Update(event.GuildID, func(settings *GuildSettings) {
+		for _, opt := range options {
+			switch opt.Name {
+			case "search-source":
+				value := opt.StringValue()
+				settings.SearchType = &value
+			case "default-volume":
+				value := int(opt.IntValue())
+				settings.DefaultVolume = &value
+			case "dj-role":
+				settings.DJRole = opt.RoleValue(nil, "").ID
+			case "music-channel":
+				settings.MusicChannel = opt.ChannelValue(nil).ID
+			case "idle-timeout":
+				value := int(opt.IntValue()) * 60
+				settings.IdleTimeout = &value
+			case "autoplay":
+				settings.Autoplay = opt.BoolValue()
+			case "max-queue-length":
+				settings.MaxQueueLength = int(opt.IntValue())
+			case "announce-now-playing":
+				settings.AnnounceNowPlaying = opt.BoolValue()
+			case "delete-old-announcements":
+				settings.DeleteAnnouncements = opt.BoolValue()
+			}
+		}
+	})
+	if err != nil {
+		return b.SendResponse(event.Interaction, "Settings Error",
+			fmt.Sprintf("%s Could not save settings: `%s`", IconError, err), ColorError)
+	}
+
+	return b.viewGuildSettings(event)
+}
+
+func (b *Bot) resetGuildSettings(event *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	key := ""
+	if len(options) > 0 {
+		key = options[0].StringValue()
+	}
+
+	_, err := b.GuildSettings.Update(event.GuildID, func(settings *GuildSettings) {
+		switch key {
+		case "search-source":
+			settings.SearchType = nil
+		case "default-volume":
+			settings.DefaultVolume = nil
+		case "dj-role":
+			settings.DJRole = ""
+		case "music-channel":
+			settings.MusicChannel = ""
+		case "idle-timeout":
+			settings.IdleTimeout = nil
+		case "autoplay":
+			settings.Autoplay = false
+		case "max-queue-length":
+			settings.MaxQueueLength = 0
+		case "announce-now-playing":
+			settings.AnnounceNowPlaying = false
+		case "delete-old-announcements":
+			settings.DeleteAnnouncements = false
+		default:
+			*settings = GuildSettings{}
+		}
+	})
+	if err != nil {
+		return b.SendResponse(event.Interaction, "Settings Error",
+			fmt.Sprintf("%s Could not save settings: `%s`", IconError, err), ColorError)
+	}
+
+	return b.viewGuildSettings(event)
+}
+
+func onOff(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
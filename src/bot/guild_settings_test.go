@@ -0,0 +1,33 @@
+package bot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGuildSettingsUpdate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	manager, err := NewGuildSettingsManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Update("guild", func(settings *GuildSettings) { settings.Autoplay = true }); err != nil {
+		t.Fatal(err)
+	}
+
+	// A change that can't be saved is not kept either
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := manager.Update("guild", func(settings *GuildSettings) { settings.Autoplay = false })
+	if err == nil {
+		t.Fatal("got no error saving into a file")
+	}
+	if !settings.Autoplay || !manager.Get("guild").Autoplay {
+		t.Errorf("unsaved change was kept: got %+v", manager.Get("guild"))
+	}
+}
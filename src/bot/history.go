@@ -0,0 +1,214 @@
+package bot
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgolink/v3/lavalink"
+
+	"jukeboxitus/src/bot/store"
+)
+
+// PlayEnd is why a recorded play ended.
+type PlayEnd string
+
+const (
+	PlayFinished   PlayEnd = "finished"
+	PlaySkipped    PlayEnd = "skipped"
+	PlayStopped    PlayEnd = "stopped"
+	PlayLoadFailed PlayEnd = "loadFailed"
+)
+
+// playEnd maps the reason Lavalink gives for a track ending. Tracks are
+// replaced when they are skipped, /play now and the like.
+func playEnd(reason lavalink.TrackEndReason) PlayEnd {
+	switch reason {
+	case lavalink.TrackEndReasonFinished:
+		return PlayFinished
+	case lavalink.TrackEndReasonReplaced:
+		return PlaySkipped
+	case lavalink.TrackEndReasonLoadFailed:
+		return PlayLoadFailed
+	default:
+		return PlayStopped
+	}
+}
+
+// Play is a track played in a guild, recorded when it ends.
+type Play struct {
+	GuildID string `json:"guildId"`
+	// Identifier is the track's source and identifier, e.g.
+	// "youtube:dQw4w9WgXcQ".
+	Identifier  string            `json:"identifier"`
+	Title       string            `json:"title"`
+	Author      string            `json:"author,omitempty"`
+	URI         string            `json:"uri,omitempty"`
+	Length      lavalink.Duration `json:"lengthMs,omitempty"`
+	Stream      bool              `json:"stream,omitempty"`
+	RequesterID string            `json:"requesterId,omitempty"`
+	StartedAt   time.Time         `json:"startedAt"`
+	// Listened is how long the track played, without pauses.
+	Listened lavalink.Duration `json:"listenedMs"`
+	Reason   PlayEnd           `json:"reason"`
+}
+
+// History records the tracks played in every guild. Plays are appended to
+// history.jsonl in the data directory and kept in memory for /stats.
+type History struct {
+	file *store.JSONLines[Play]
+
+	mu      sync.RWMutex
+	plays   map[string][]Play
+	playing map[string]*playingTrack
+}
+
+// playingTrack is a play that has started but not ended yet.
+type playingTrack struct {
+	play     Play
+	pausedAt time.Time
+	paused   time.Duration
+}
+
+// NewHistory loads the recorded plays from dataDir. The history is usable
+// even when an error is returned, with the plays that could be read.
+func NewHistory(dataDir string) (*History, error) {
+	h := &History{
+		file:    store.NewJSONLines[Play](dataDir, "history.jsonl"),
+		plays:   make(map[string][]Play),
+		playing: make(map[string]*playingTrack),
+	}
+	plays, err := h.file.Load()
+	for _, play := range plays {
+		h.plays[play.GuildID] = append(h.plays[play.GuildID], play)
+	}
+	return h, err
+}
+
+// Start notes that a track started playing in a guild.
+func (h *History) Start(guildID string, track lavalink.Track, now time.Time) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.playing[guildID] = &playingTrack{play: newPlay(guildID, track, now)}
+}
+
+// Pause and Resume keep pauses out of the time a track was listened to.
+func (h *History) Pause(guildID string, now time.Time) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if current, ok := h.playing[guildID]; ok && current.pausedAt.IsZero() {
+		current.pausedAt = now
+	}
+}
+
+func (h *History) Resume(guildID string, now time.Time) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if current, ok := h.playing[guildID]; ok && !current.pausedAt.IsZero() {
+		current.paused += now.Sub(current.pausedAt)
+		current.pausedAt = time.Time{}
+	}
+}
+
+// End records the play of a track that ended. Tracks that failed before they
+// started are recorded too, with nothing listened.
+func (h *History) End(guildID string, track lavalink.Track, reason lavalink.TrackEndReason, now time.Time) error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	play := newPlay(guildID, track, now)
+	if current, ok := h.playing[guildID]; ok && current.play.Identifier == play.Identifier {
+		play = current.finish(now)
+		delete(h.playing, guildID)
+	}
+	play.Reason = playEnd(reason)
+	h.plays[guildID] = append(h.plays[guildID], play)
+	h.mu.Unlock()
+
+	return h.file.Append(play)
+}
+
+// Leave records the track playing when the bot left a guild's voice channel,
+// since destroying the player doesn't end it with an event.
+func (h *History) Leave(guildID string, now time.Time) error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	current, ok := h.playing[guildID]
+	if !ok {
+		h.mu.Unlock()
+		return nil
+	}
+	delete(h.playing, guildID)
+	play := current.finish(now)
+	play.Reason = PlayStopped
+	h.plays[guildID] = append(h.plays[guildID], play)
+	h.mu.Unlock()
+
+	return h.file.Append(play)
+}
+
+// Plays returns the plays recorded in a guild from since on, oldest first. A
+// zero since returns all of them. Plays are sorted by start since a guild
+// plays one track at a time.
+func (h *History) Plays(guildID string, since time.Time) []Play {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	plays := h.plays[guildID]
+	start, _ := slices.BinarySearchFunc(plays, since, func(play Play, since time.Time) int {
+		return play.StartedAt.Compare(since)
+	})
+	return slices.Clone(plays[start:])
+}
+
+// finish returns the play with the time listened until now, without pauses.
+func (p *playingTrack) finish(now time.Time) Play {
+	play := p.play
+	paused := p.paused
+	if !p.pausedAt.IsZero() {
+		paused += now.Sub(p.pausedAt)
+	}
+	listened := now.Sub(play.StartedAt) - paused
+	if !play.Stream && play.Length > 0 {
+		listened = min(listened, time.Duration(play.Length)*time.Millisecond)
+	}
+	play.Listened = lavalink.Duration(max(listened, 0).Milliseconds())
+	return play
+}
+
+func newPlay(guildID string, track lavalink.Track, now time.Time) Play {
+	info := trackInfo(track)
+	play := Play{
+		GuildID:     guildID,
+		Identifier:  info.SourceName + ":" + info.Identifier,
+		Title:       info.Title,
+		Author:      info.Author,
+		Length:      info.Length,
+		Stream:      info.IsStream,
+		RequesterID: trackData(track).RequesterID,
+		StartedAt:   now,
+	}
+	if info.URI != nil {
+		play.URI = *info.URI
+	}
+	// Placeholders that failed to load are only known by what loads them
+	if identifiers := trackData(track).Identifiers; isPlaceholder(track) && len(identifiers) > 0 {
+		play.Identifier = identifiers[0]
+	}
+	return play
+}
@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgolink/v3/lavalink"
+)
+
+func TestHistory(t *testing.T) {
+	dir := t.TempDir()
+	history, err := NewHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, time.March, 1, 20, 0, 0, 0, time.UTC)
+	track := requestedBy(lavalink.Track{Encoded: "QAAA", Info: lavalink.TrackInfo{
+		Identifier: "dQw4w9WgXcQ",
+		SourceName: "youtube",
+		Title:      "Never Gonna Give You Up",
+		Author:     "Rick Astley",
+		Length:     213000,
+	}}, "42")
+
+	// Paused for a minute, then skipped
+	history.Start("guild", track, start)
+	history.Pause("guild", start.Add(30*time.Second))
+	history.Resume("guild", start.Add(90*time.Second))
+	if err := history.End("guild", track, lavalink.TrackEndReasonReplaced, start.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A placeholder that could not be loaded never started
+	failed := newPlaceholder(lavalink.TrackInfo{Title: "Wake Me Up", Author: "Avicii"}, "ytsearch:Avicii - Wake Me Up")
+	if err := history.End("guild", failed, lavalink.TrackEndReasonLoadFailed, start.Add(3*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Listened no longer than the track lasts
+	history.Start("guild", track, start.Add(4*time.Minute))
+	if err := history.Leave("guild", start.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		identifier string
+		listened   lavalink.Duration
+		reason     PlayEnd
+	}{
+		{"youtube:dQw4w9WgXcQ", 60000, PlaySkipped},
+		{"ytsearch:Avicii - Wake Me Up", 0, PlayLoadFailed},
+		{"youtube:dQw4w9WgXcQ", 213000, PlayStopped},
+	}
+	check := func(plays []Play) {
+		t.Helper()
+		if len(plays) != len(want) {
+			t.Fatalf("got %d plays, want %d", len(plays), len(want))
+		}
+		for i, play := range plays {
+			if play.Identifier != want[i].identifier || play.Listened != want[i].listened || play.Reason != want[i].reason {
+				t.Errorf("play %d: got %+v", i, play)
+			}
+		}
+		if plays[0].RequesterID != "42" {
+			t.Errorf("got requester %q", plays[0].RequesterID)
+		}
+	}
+	check(history.Plays("guild", time.Time{}))
+
+	// Plays are read back from the file
+	reloaded, err := NewHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(reloaded.Plays("guild", time.Time{}))
+	if plays := reloaded.Plays("guild", start.Add(time.Minute)); len(plays) != 2 {
+		t.Errorf("got %d plays since the first ended, want 2", len(plays))
+	}
+	if len(countedPlays(reloaded.Plays("guild", time.Time{}))) != 2 {
+		t.Error("failed plays are counted")
+	}
+}
@@ -0,0 +1,74 @@
+// Package icy reads the stream title Icecast and Shoutcast servers embed in
+// their audio streams.
+package icy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrNoMetadata is returned for streams that do not send ICY metadata.
+var ErrNoMetadata = errors.New("stream has no ICY metadata")
+
+// StreamTitle connects to a stream and returns the title of its first
+// metadata block, which is empty when the station does not send one. Only
+// the audio up to that block is read.
+func StreamTitle(ctx context.Context, client *http.Client, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Icy-MetaData", "1")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("stream returned %s", resp.Status)
+	}
+	metaInt, err := strconv.Atoi(resp.Header.Get("Icy-Metaint"))
+	if err != nil || metaInt <= 0 {
+		return "", ErrNoMetadata
+	}
+
+	// The metadata block follows metaInt bytes of audio, its length is given
+	// in units of 16 bytes
+	if _, err := io.CopyN(io.Discard, resp.Body, int64(metaInt)); err != nil {
+		return "", err
+	}
+	var length [1]byte
+	if _, err := io.ReadFull(resp.Body, length[:]); err != nil {
+		return "", err
+	}
+	block := make([]byte, int(length[0])*16)
+	if _, err := io.ReadFull(resp.Body, block); err != nil {
+		return "", err
+	}
+	return parseStreamTitle(string(block)), nil
+}
+
+// parseStreamTitle extracts StreamTitle from a metadata block such as
+// "StreamTitle='Artist - Song';".
+func parseStreamTitle(block string) string {
+	const key = "StreamTitle='"
+	start := strings.Index(block, key)
+	if start < 0 {
+		return ""
+	}
+	value := block[start+len(key):]
+	if end := strings.Index(value, "';"); end >= 0 {
+		value = value[:end]
+	} else {
+		value = strings.TrimRight(value, "\x00")
+		value = strings.TrimSuffix(value, "'")
+	}
+	return strings.TrimSpace(value)
+}
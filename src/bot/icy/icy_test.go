@@ -0,0 +1,143 @@
+package icy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stream builds the start of an ICY stream: metaInt bytes of audio followed
+// by a metadata block, padded to a multiple of 16 bytes.
+func stream(metaInt int, meta string) []byte {
+	var buf bytes.Buffer
+	buf.Write(bytes.Repeat([]byte{0xff}, metaInt))
+	length := (len(meta) + 15) / 16
+	buf.WriteByte(byte(length))
+	buf.WriteString(meta)
+	buf.Write(make([]byte, length*16-len(meta)))
+	return buf.Bytes()
+}
+
+func TestStreamTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		metaInt string
+		status  int
+		body    []byte
+		// endless keeps sending audio after the body, like a real station.
+		endless bool
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "metadata block",
+			metaInt: "16",
+			body:    stream(16, "StreamTitle='Daft Punk - One More Time';StreamUrl='';"),
+			endless: true,
+			want:    "Daft Punk - One More Time",
+		},
+		{
+			name:    "empty metadata block",
+			metaInt: "16",
+			body:    stream(16, ""),
+			want:    "",
+		},
+		{
+			name:    "largest metadata block",
+			metaInt: "8192",
+			body:    stream(8192, "StreamTitle='"+string(bytes.Repeat([]byte("a"), 4000))+"';"),
+			endless: true,
+			want:    string(bytes.Repeat([]byte("a"), 4000)),
+		},
+		{
+			name:    "missing metaint",
+			body:    []byte("audio"),
+			wantErr: ErrNoMetadata,
+		},
+		{
+			name:    "invalid metaint",
+			metaInt: "-1",
+			body:    []byte("audio"),
+			wantErr: ErrNoMetadata,
+		},
+		{
+			name:    "truncated audio",
+			metaInt: "16",
+			body:    bytes.Repeat([]byte{0xff}, 10),
+			wantErr: io.EOF,
+		},
+		{
+			name:    "truncated block",
+			metaInt: "16",
+			body:    append(append(bytes.Repeat([]byte{0xff}, 16), 4), "StreamTitle='Cut"...),
+			wantErr: io.ErrUnexpectedEOF,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Icy-MetaData") != "1" {
+					t.Errorf("got Icy-MetaData %q", r.Header.Get("Icy-MetaData"))
+				}
+				if test.metaInt != "" {
+					w.Header().Set("Icy-MetaInt", test.metaInt)
+				}
+				w.Write(test.body)
+				for test.endless {
+					if _, err := w.Write(make([]byte, 1024)); err != nil {
+						return
+					}
+				}
+			}))
+			defer server.Close()
+
+			got, err := StreamTitle(context.Background(), server.Client(), server.URL)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("got error %v, want %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStreamTitleStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such mount", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if _, err := StreamTitle(context.Background(), server.Client(), server.URL); err == nil {
+		t.Error("got no error for a missing mount")
+	}
+}
+
+func TestParseStreamTitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		block string
+		want  string
+	}{
+		{"title", "StreamTitle='Artist - Song';", "Artist - Song"},
+		{"with stream url", "StreamTitle='Artist - Song';StreamUrl='https://example.com';", "Artist - Song"},
+		{"semicolon in title", "StreamTitle='a;b';", "a;b"},
+		{"apostrophe in title", "StreamTitle='Don't Stop';", "Don't Stop"},
+		{"padding", "StreamTitle=' Song ';\x00\x00\x00", "Song"},
+		{"unterminated", "StreamTitle='Song'\x00\x00\x00", "Song"},
+		{"empty title", "StreamTitle='';", ""},
+		{"no title", "StreamUrl='https://example.com';", ""},
+		{"empty block", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseStreamTitle(test.block); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
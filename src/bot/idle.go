@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/disgoorg/log"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// idleTimers disconnects from voice channels where nothing has been playing
+// for longer than the guild's idle timeout.
+type idleTimers struct {
+	mu     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+func (b *Bot) startIdleTimer(guildID string) {
+	timeout := b.Settings(guildID).IdleTimeout
+	if timeout <= 0 {
+		return
+	}
+
+	b.idle.mu.Lock()
+	defer b.idle.mu.Unlock()
+	if b.idle.timers == nil {
+		b.idle.timers = make(map[string]*time.Timer)
+	}
+	if timer, ok := b.idle.timers[guildID]; ok {
+		timer.Stop()
+	}
+	b.idle.timers[guildID] = time.AfterFunc(timeout, func() { b.onIdle(guildID) })
+}
+
+func (b *Bot) stopIdleTimer(guildID string) {
+	b.idle.mu.Lock()
+	defer b.idle.mu.Unlock()
+	if timer, ok := b.idle.timers[guildID]; ok {
+		timer.Stop()
+		delete(b.idle.timers, guildID)
+	}
+}
+
+func (b *Bot) onIdle(guildID string) {
+	b.idle.mu.Lock()
+	delete(b.idle.timers, guildID)
+	b.idle.mu.Unlock()
+
+	// Something may have started playing right when the timer fired
+	player := b.Lavalink.ExistingPlayer(snowflake.MustParse(guildID))
+	if player != nil && player.Track() != nil && !player.Paused() {
+		return
+	}
+
+	log.Infof("leaving voice channel in guild %s after being idle", guildID)
+	if err := b.Session.ChannelVoiceJoinManual(guildID, "", false, false); err != nil {
+		log.Error("failed to leave voice channel: ", err)
+	}
+}
@@ -0,0 +1,276 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/log"
+)
+
+// progressInterval is how often background loading reports its progress.
+const progressInterval = 3 * time.Second
+
+// placeholderLoadTime bounds loading the placeholders a command saves, so it
+// responds well within the 15 minutes its interaction token is valid.
+const placeholderLoadTime = 10 * time.Minute
+
+// loadPlaceholder turns a placeholder into a playable track by loading its
+// identifiers in order. Tracks that are already loaded are returned as is.
+func (b *Bot) loadPlaceholder(ctx context.Context, track lavalink.Track) (lavalink.Track, error) {
+	if !isPlaceholder(track) {
+		return track, nil
+	}
+
+	data := trackData(track)
+	for _, identifier := range data.Identifiers {
+		result, err := b.Lavalink.BestNode().LoadTracks(ctx, identifier)
+		if err != nil {
+			return track, err
+		}
+
+		var loaded *lavalink.Track
+		switch result := result.Data.(type) {
+		case lavalink.Track:
+			loaded = &result
+		case lavalink.Search:
+			if len(result) > 0 {
+				loaded = &result[0]
+			}
+		case lavalink.Playlist:
+			if len(result.Tracks) > 0 {
+				loaded = &result.Tracks[0]
+			}
+		}
+		if loaded != nil {
+			data.Identifiers = nil
+			return withTrackData(*loaded, data), nil
+		}
+	}
+	return track, fmt.Errorf("nothing found for %q", track.Info.Title)
+}
+
+// loadPlaceholders loads the placeholders among tracks one by one, for
+// saving them. Placeholders that fail to load, or are still left once
+// placeholderLoadTime passed, are dropped and counted.
+func (b *Bot) loadPlaceholders(tracks []lavalink.Track) ([]lavalink.Track, int) {
+	all, cancelAll := context.WithTimeout(context.Background(), placeholderLoadTime)
+	defer cancelAll()
+
+	loaded := make([]lavalink.Track, 0, len(tracks))
+	failed := 0
+	for _, track := range tracks {
+		ctx, cancel := context.WithTimeout(all, b.Config().LoadTracksTimeout())
+		track, err := b.loadPlaceholder(ctx, track)
+		cancel()
+		if err != nil {
+			failed++
+			continue
+		}
+		loaded = append(loaded, track)
+	}
+	return loaded, failed
+}
+
+// placeholderOf drops the encoded data of a loaded track, keeping what is
+// needed to load it again and its user data. Tracks without a URI are kept
+// as they are.
+func placeholderOf(track lavalink.Track) lavalink.Track {
+	if isPlaceholder(track) || track.Info.URI == nil {
+		return track
+	}
+	data := trackData(track)
+	data.Identifiers = []string{*track.Info.URI}
+	return withTrackData(lavalink.Track{Info: track.Info}, data)
+}
+
+// playNext plays the next track of the queue, loading it first if it is a
+// placeholder. Tracks that cannot be loaded are skipped. It reports false
+// when the queue ran out.
+func (b *Bot) playNext(player disgolink.Player, queue *Queue) (lavalink.Track, bool, error) {
+	for {
+		next, ok := queue.Next()
+		if !ok {
+			return lavalink.Track{}, false, nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), b.Config().LoadTracksTimeout())
+		track, err := b.loadPlaceholder(ctx, next)
+		cancel()
+		if isPlaceholder(next) {
+			b.placeholderLoaded(next, err == nil)
+		}
+		if err != nil {
+			log.Errorf("skipping %q, failed to load it: %s", next.Info.Title, err)
+			if err := b.History.End(player.GuildID().String(), next, lavalink.TrackEndReasonLoadFailed, time.Now()); err != nil {
+				log.Error("failed to record the play: ", err)
+			}
+			continue
+		}
+
+		return track, true, player.Update(context.Background(), playOpts(track)...)
+	}
+}
+
+// prefetchNext loads the placeholder at the front of the queue while the
+// current track plays, so the next one starts without delay.
+func (b *Bot) prefetchNext(guildID string) {
+	queue := b.Queues.Get(guildID)
+	next, ok := queue.Peek()
+	if !ok || !isPlaceholder(next) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.Config().LoadTracksTimeout())
+	defer cancel()
+	track, err := b.loadPlaceholder(ctx, next)
+	if err != nil {
+		// playNext tries again and skips it if it still fails
+		return
+	}
+	if queue.ReplaceFront(next, track) {
+		b.placeholderLoaded(next, true)
+	}
+}
+
+// lazyBatch is the placeholders queued by one command, whose response shows
+// how many of them were loaded so far.
+type lazyBatch struct {
+	guildID   string
+	channelID string
+	messageID string
+	title     string
+	header    string
+	artwork   string
+
+	total    int
+	loaded   int
+	failed   int
+	reported time.Time
+}
+
+// progress renders how many placeholders of the batch were loaded.
+func (l lazyBatch) progress() string {
+	done := l.loaded + l.failed
+	progress := fmt.Sprintf("\n%s Loaded **%d/%d** tracks\n`%s`", IconQueue, done, l.total, progressBar(done, l.total, 20))
+	if l.failed > 0 {
+		progress += fmt.Sprintf("\n%s **%d** tracks could not be loaded and were skipped.", IconWarning, l.failed)
+	}
+	return progress
+}
+
+// lazyBatches keeps the batches of placeholders still loading, by the ID of
+// the interaction that queued them.
+type lazyBatches struct {
+	mu      sync.Mutex
+	batches map[string]*lazyBatch
+}
+
+func (l *lazyBatches) add(id string, batch lazyBatch) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.batches == nil {
+		l.batches = make(map[string]*lazyBatch)
+	}
+	l.batches[id] = &batch
+}
+
+// show sets the message showing the progress of a batch, once the response
+// was sent.
+func (l *lazyBatches) show(id string, message lazyBatch) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if batch, ok := l.batches[id]; ok {
+		batch.channelID, batch.messageID = message.channelID, message.messageID
+		batch.title, batch.header, batch.artwork = message.title, message.header, message.artwork
+	}
+}
+
+// done counts a placeholder of a batch as loaded or failed. It returns the
+// batch when its message is due an update, at most every progressInterval
+// and once all of its placeholders were loaded.
+func (l *lazyBatches) done(id string, ok bool) (lazyBatch, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	batch, found := l.batches[id]
+	if !found {
+		return lazyBatch{}, false
+	}
+	if ok {
+		batch.loaded++
+	} else {
+		batch.failed++
+	}
+	if batch.loaded+batch.failed >= batch.total {
+		delete(l.batches, id)
+	} else if time.Since(batch.reported) < progressInterval {
+		return lazyBatch{}, false
+	}
+	batch.reported = time.Now()
+	return *batch, batch.messageID != ""
+}
+
+// stop forgets the batches of a guild whose queue is gone.
+func (l *lazyBatches) stop(guildID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, batch := range l.batches {
+		if batch.guildID == guildID {
+			delete(l.batches, id)
+		}
+	}
+}
+
+// reportPlaceholders responds to the command that queued a batch of
+// placeholders, with their progress below description. The batch is added
+// before the placeholders are queued, since the first ones may load before
+// the response is sent.
+func (b *Bot) reportPlaceholders(event *discordgo.InteractionCreate, title string, description string, artwork string, total int) error {
+	starting := lazyBatch{total: total}
+	if err := b.SendComplexResponse(event.Interaction, title, description+starting.progress(), artwork, ColorSuccess); err != nil {
+		return err
+	}
+
+	// Interaction tokens expire long before a large playlist is played, so
+	// the message is edited through its channel
+	message, err := b.Session.InteractionResponse(event.Interaction)
+	if err != nil {
+		log.Debug("failed to get the playlist response: ", err)
+		return nil
+	}
+	b.lazyBatches.show(event.ID, lazyBatch{
+		channelID: message.ChannelID,
+		messageID: message.ID,
+		title:     title,
+		header:    description,
+		artwork:   artwork,
+	})
+	return nil
+}
+
+// placeholderLoaded counts a placeholder as loaded or failed in the response
+// that queued it.
+func (b *Bot) placeholderLoaded(track lavalink.Track, ok bool) {
+	batch, due := b.lazyBatches.done(trackData(track).Batch, ok)
+	if !due {
+		return
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       batch.title,
+		Description: batch.header + batch.progress(),
+		Color:       ColorSuccess,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Jukeboxitus Music",
+		},
+	}
+	if batch.artwork != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: batch.artwork}
+	}
+	if _, err := b.Session.ChannelMessageEditEmbed(batch.channelID, batch.messageID, embed); err != nil {
+		log.Debug("failed to update playlist progress: ", err)
+	}
+}
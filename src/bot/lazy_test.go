@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLazyBatches(t *testing.T) {
+	var batches lazyBatches
+	batches.add("1", lazyBatch{guildID: "guild", total: 3})
+	batches.add("2", lazyBatch{guildID: "other", total: 5})
+
+	// Nothing to edit before the response is shown
+	if _, due := batches.done("1", true); due {
+		t.Error("due before the message is known")
+	}
+	batches.show("1", lazyBatch{channelID: "channel", messageID: "message", title: "Playlist Added"})
+
+	// Updates wait for progressInterval, except the last one
+	if _, due := batches.done("1", false); due {
+		t.Error("due right after the last update")
+	}
+	batch, due := batches.done("1", true)
+	if !due || batch.loaded != 2 || batch.failed != 1 || batch.title != "Playlist Added" {
+		t.Fatalf("got %+v, due %v", batch, due)
+	}
+	if progress := batch.progress(); !strings.Contains(progress, "**3/3**") || !strings.Contains(progress, "**1** tracks could not be loaded") {
+		t.Errorf("got progress %q", progress)
+	}
+
+	// Finished batches, unknown ones and those of a guild that was left are
+	// not counted anymore
+	if _, due := batches.done("1", true); due {
+		t.Error("a finished batch is still counted")
+	}
+	batches.stop("other")
+	if _, due := batches.done("2", true); due || len(batches.batches) != 0 {
+		t.Errorf("batches left: %+v", batches.batches)
+	}
+}
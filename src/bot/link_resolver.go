@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/log"
+	"github.com/disgoorg/snowflake/v2"
+
+	bot_config "jukeboxitus/src/bot/config"
+	"jukeboxitus/src/bot/resolver"
+)
+
+// loadResolved handles links Lavalink cannot play itself. The link's metadata
+// is searched on the guild's search source. Only the first playable item is
+// searched right away, the rest is left pending so big playlists don't hold up
+// the response.
+func (b *Bot) loadResolved(linkResolver resolver.Resolver, link string, settings Settings, playlistOpts playlistOptions) (*loadedTracks, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.Config().LoadTracksTimeout())
+	defer cancel()
+
+	result, err := linkResolver.Resolve(ctx, link)
+	if err != nil {
+		return nil, &loadError{Title: "Search Error", Icon: IconError, Color: ColorError,
+			Message: fmt.Sprintf("Could not read the %s link: `%s`", linkResolver.Name(), err)}
+	}
+
+	loaded := &loadedTracks{ArtworkURL: result.ArtworkURL}
+	items := result.Items
+	if len(items) > 1 {
+		var from int
+		if items, from, err = selectTracks(result.Items, -1, playlistOpts); err != nil {
+			return nil, playlistError(err)
+		}
+		loaded.From = fmt.Sprintf("%s playlist: `%s`", linkResolver.Name(), result.Name)
+		loaded.Notes = rangeNote(from, len(items), len(result.Items), playlistOpts)
+	}
+
+	for i, item := range items {
+		if first, err := b.resolveItem(ctx, item, settings.SearchType); err == nil {
+			loaded.Tracks = []lavalink.Track{first}
+			loaded.Pending = items[i+1:]
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(loaded.Tracks) == 0 {
+		return nil, &loadError{Title: "No Results", Icon: IconEmpty, Color: ColorDefault,
+			Message: fmt.Sprintf("Nothing playable found for: `%s`", link)}
+	}
+
+	loaded.ArtworkURL = cmp.Or(loaded.ArtworkURL, trackArtwork(loaded.Tracks[0]))
+	loaded.limit(b.Config().MaxPlaylistTracks())
+	return loaded, nil
+}
+
+// queuePending searches the pending items of a resolved playlist in the
+// background, showing the progress below header in the response.
+func (b *Bot) queuePending(event *discordgo.InteractionCreate, header string, artworkURL string, items []resolver.Item, settings Settings) error {
+	progress := func(done int, failed int) {
+		description := fmt.Sprintf("%s\n%s Searched **%d/%d** tracks\n`%s`", header, IconSearch, done, len(items), progressBar(done, len(items), 20))
+		if failed > 0 {
+			description += fmt.Sprintf("\n%s **%d** tracks could not be found.", IconWarning, failed)
+		}
+		if err := b.EditResponse(event.Interaction, "Playlist Added", description, artworkURL, ColorSuccess); err != nil {
+			log.Debug("failed to update playlist progress: ", err)
+		}
+	}
+	progress(0, 0)
+
+	go b.queueResolvedItems(event.GuildID, event.Member.User.ID, items, settings, progress)
+	return nil
+}
+
+// queueResolvedItems searches the remaining items one by one and queues them
+// in order for requesterID, calling progress every few seconds and once it
+// is done. It stops when the bot leaves the voice channel or the queue is
+// full.
+func (b *Bot) queueResolvedItems(guildID string, requesterID string, items []resolver.Item, settings Settings, progress func(done int, failed int)) {
+	done, failed := 0, 0
+	reported := time.Now()
+	defer func() {
+		progress(done, failed)
+		if failed > 0 {
+			log.Infof("%d tracks of a resolved playlist could not be found in guild %s", failed, guildID)
+		}
+	}()
+
+	for _, item := range items {
+		player := b.Lavalink.ExistingPlayer(snowflake.MustParse(guildID))
+		if player == nil {
+			return
+		}
+		queue := b.Queues.Get(guildID)
+		if settings.MaxQueueLength > 0 && queue.Len() >= settings.MaxQueueLength {
+			log.Infof("queue of guild %s is full, %d tracks were not loaded", guildID, len(items)-done)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), b.Config().LoadTracksTimeout())
+		track, err := b.resolveItem(ctx, item, settings.SearchType)
+		cancel()
+		done++
+		if err != nil {
+			failed++
+			continue
+		}
+		track = requestedBy(track, requesterID)
+
+		if player.Track() == nil {
+			if err := player.Update(context.Background(), lavalink.WithTrack(track)); err != nil {
+				log.Error("Failed to play resolved track: ", err)
+			}
+		} else {
+			queue.Add(track)
+		}
+
+		if time.Since(reported) >= progressInterval {
+			progress(done, failed)
+			reported = time.Now()
+		}
+	}
+}
+
+// resolveItem finds a playable track for an item. When the item has an ISRC
+// and the source is YouTube, the ISRC is tried first since it usually finds
+// the official upload.
+func (b *Bot) resolveItem(ctx context.Context, item resolver.Item, searchType bot_config.SearchType) (lavalink.Track, error) {
+	for _, query := range itemQueries(item, searchType) {
+		result, err := b.Lavalink.BestNode().LoadTracks(ctx, query)
+		if err != nil {
+			return lavalink.Track{}, err
+		}
+		switch data := result.Data.(type) {
+		case lavalink.Search:
+			if len(data) > 0 {
+				return data[0], nil
+			}
+		case lavalink.Track:
+			return data, nil
+		}
+	}
+	return lavalink.Track{}, fmt.Errorf("nothing found for %q", item.Query())
+}
+
+// itemQueries returns the searches that may find an item, best first.
+func itemQueries(item resolver.Item, searchType bot_config.SearchType) []string {
+	var queries []string
+	if item.ISRC != "" && (searchType == bot_config.YouTube || searchType == bot_config.YouTubeMusic) {
+		queries = append(queries, applySearchType(searchType, strconv.Quote(item.ISRC)))
+	}
+	return append(queries, applySearchType(searchType, item.Query()))
+}
+
+// itemPlaceholder queues an item without searching it yet.
+func itemPlaceholder(item resolver.Item, searchType bot_config.SearchType) lavalink.Track {
+	info := lavalink.TrackInfo{
+		Title:  item.Title,
+		Author: strings.Join(item.Artists, ", "),
+		Length: lavalink.Duration(item.Duration.Milliseconds()),
+	}
+	if item.ArtworkURL != "" {
+		info.ArtworkURL = &item.ArtworkURL
+	}
+	return newPlaceholder(info, itemQueries(item, searchType)...)
+}
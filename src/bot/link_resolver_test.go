@@ -0,0 +1,33 @@
+package bot
+
+import (
+	"slices"
+	"testing"
+
+	bot_config "jukeboxitus/src/bot/config"
+	"jukeboxitus/src/bot/resolver"
+)
+
+func TestItemQueries(t *testing.T) {
+	item := resolver.Item{Title: "Wake Me Up", Artists: []string{"Avicii"}, ISRC: "SEUM71301326"}
+	tests := []struct {
+		name       string
+		item       resolver.Item
+		searchType bot_config.SearchType
+		want       []string
+	}{
+		{"ISRC first on YouTube", item, bot_config.YouTube,
+			[]string{`ytsearch:"SEUM71301326"`, "ytsearch:Avicii - Wake Me Up"}},
+		{"ISRC first on YouTube Music", item, bot_config.YouTubeMusic,
+			[]string{`ytmsearch:"SEUM71301326"`, "ytmsearch:Avicii - Wake Me Up"}},
+		{"no ISRC search elsewhere", item, bot_config.SoundCloud,
+			[]string{"scsearch:Avicii - Wake Me Up"}},
+		{"without ISRC", resolver.Item{Title: "Come Together", Artists: []string{"The Beatles"}}, bot_config.YouTube,
+			[]string{"ytsearch:The Beatles - Come Together"}},
+	}
+	for _, test := range tests {
+		if got := itemQueries(test.item, test.searchType); !slices.Equal(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
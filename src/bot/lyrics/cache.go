@@ -0,0 +1,220 @@
+package lyrics
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"jukeboxitus/src/bot/store"
+)
+
+// cacheSaveDelay is how long the cache waits after a change before saving
+// it, so a burst of lookups is written once.
+const cacheSaveDelay = 5 * time.Second
+
+// Cache remembers lyrics, and songs that have none for a while, so the same
+// song is not looked up again. The least recently used entries are evicted
+// first, and the cache is kept on disk across restarts.
+type Cache struct {
+	file        *store.JSONFile[[]CacheEntry]
+	size        int
+	notFoundTTL time.Duration
+
+	mu sync.Mutex
+	// order has the most recently used entry in front, index finds an entry
+	// by any of its keys.
+	order *list.List
+	index map[string]*list.Element
+	// saveTimer is set while a save is scheduled.
+	saveTimer *time.Timer
+
+	// saveMu keeps saves in order, they run without holding mu.
+	saveMu sync.Mutex
+	// OnSaveError is called when saving in the background fails.
+	OnSaveError func(err error)
+}
+
+// CacheEntry is what the cache stores for a song, under every key it was
+// looked up with. Lyrics is nil for songs that had none.
+type CacheEntry struct {
+	Keys    []string  `json:"keys"`
+	Lyrics  *Lyrics   `json:"lyrics,omitempty"`
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// NewCache loads the cache kept in dataDir. It holds at most size songs, and
+// forgets songs without lyrics after notFoundTTL. A stored cache that can't
+// be read is replaced by an empty one, returned along with the error.
+func NewCache(dataDir string, size int, notFoundTTL time.Duration) (*Cache, error) {
+	c := &Cache{
+		file:        store.NewJSONFile[[]CacheEntry](dataDir, "lyrics_cache.json"),
+		size:        size,
+		notFoundTTL: notFoundTTL,
+		order:       list.New(),
+		index:       make(map[string]*list.Element),
+	}
+	entries, err := c.file.Load()
+	if err != nil {
+		return c, err
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.expired(now) || c.order.Len() >= size {
+			continue
+		}
+		c.insert(entry, false)
+	}
+	return c, nil
+}
+
+func (e CacheEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && now.After(e.Expires)
+}
+
+// CacheKeys returns the keys a query is cached under: the track it was
+// played from, its ISRC, and its normalized artist and title.
+func CacheKeys(query Query) []string {
+	var keys []string
+	if query.Identifier != "" {
+		keys = append(keys, "track:"+query.Identifier)
+	}
+	if query.ISRC != "" {
+		keys = append(keys, "isrc:"+strings.ToUpper(query.ISRC))
+	}
+	if title := normalize(query.Title); title != "" {
+		keys = append(keys, "song:"+normalize(query.Artist)+"|"+title)
+	}
+	return keys
+}
+
+// normalize lowercases s and keeps only its letters and digits, separated by
+// single spaces, so punctuation and spacing don't make a new key.
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// SameArtist reports whether two artist names likely name the same artist,
+// ignoring case and punctuation. A name containing the other matches too, as
+// in "BTS" and "BTS (방탄소년단)" or "Calvin Harris" and "Calvin Harris & Dua
+// Lipa".
+func SameArtist(a string, b string) bool {
+	a, b = normalize(a), normalize(b)
+	if a == "" || b == "" {
+		return a == b
+	}
+	return strings.Contains(" "+a+" ", " "+b+" ") || strings.Contains(" "+b+" ", " "+a+" ")
+}
+
+// Get returns the cached lyrics of a query. ok is false when the song is not
+// cached, lyrics is nil when it is known to have none.
+func (c *Cache) Get(query Query) (lyrics *Lyrics, ok bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range CacheKeys(query) {
+		element, found := c.index[key]
+		if !found {
+			continue
+		}
+		entry := element.Value.(CacheEntry)
+		if entry.expired(time.Now()) {
+			c.remove(element)
+			continue
+		}
+		c.order.MoveToFront(element)
+		return entry.Lyrics, true
+	}
+	return nil, false
+}
+
+// Put caches the lyrics of a query, nil when it has none. The cache is saved
+// to disk in the background shortly after.
+func (c *Cache) Put(query Query, lyrics *Lyrics) {
+	if c == nil || c.size <= 0 {
+		return
+	}
+	keys := CacheKeys(query)
+	if len(keys) == 0 {
+		return
+	}
+
+	entry := CacheEntry{Keys: keys, Lyrics: lyrics}
+	if lyrics == nil {
+		entry.Expires = time.Now().Add(c.notFoundTTL)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.insert(entry, true)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	if c.saveTimer == nil {
+		c.saveTimer = time.AfterFunc(cacheSaveDelay, c.save)
+	}
+}
+
+// Flush saves the cache right away if it changed since it was last saved.
+func (c *Cache) Flush() error {
+	if c == nil {
+		return nil
+	}
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+
+	c.mu.Lock()
+	if c.saveTimer == nil {
+		c.mu.Unlock()
+		return nil
+	}
+	c.saveTimer.Stop()
+	c.saveTimer = nil
+	entries := make([]CacheEntry, 0, c.order.Len())
+	for element := c.order.Front(); element != nil; element = element.Next() {
+		entries = append(entries, element.Value.(CacheEntry))
+	}
+	c.mu.Unlock()
+
+	return c.file.Save(entries)
+}
+
+func (c *Cache) save() {
+	if err := c.Flush(); err != nil && c.OnSaveError != nil {
+		c.OnSaveError(err)
+	}
+}
+
+// insert adds an entry, replacing the entries of its keys, at the front or
+// at the back of the order.
+func (c *Cache) insert(entry CacheEntry, front bool) {
+	for _, key := range entry.Keys {
+		if element, ok := c.index[key]; ok {
+			c.remove(element)
+		}
+	}
+	var element *list.Element
+	if front {
+		element = c.order.PushFront(entry)
+	} else {
+		element = c.order.PushBack(entry)
+	}
+	for _, key := range entry.Keys {
+		c.index[key] = element
+	}
+}
+
+func (c *Cache) remove(element *list.Element) {
+	for _, key := range element.Value.(CacheEntry).Keys {
+		if c.index[key] == element {
+			delete(c.index, key)
+		}
+	}
+	c.order.Remove(element)
+}
@@ -0,0 +1,78 @@
+package lyrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewCache(dir, 2, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queen := Query{Artist: "Queen", Title: "Bohemian Rhapsody", Identifier: "youtube:fJ9rUzIMcZQ"}
+	found := &Lyrics{Artist: "Queen", Title: "Bohemian Rhapsody", Text: "Is this the real life?"}
+	cache.Put(queen, found)
+	cache.Put(Query{Artist: "Nobody", Title: "Silence"}, nil)
+
+	// Looked up under another key of the same song
+	if got, ok := cache.Get(Query{Artist: "QUEEN", Title: "Bohemian Rhapsody!"}); !ok || got.Text != found.Text {
+		t.Errorf("got %+v, %v", got, ok)
+	}
+	if got, ok := cache.Get(Query{Artist: "Nobody", Title: "Silence"}); !ok || got != nil {
+		t.Errorf("got %+v, %v for a song without lyrics", got, ok)
+	}
+
+	// Saving waits, unless flushed
+	if _, err := os.Stat(filepath.Join(dir, "lyrics_cache.json")); !os.IsNotExist(err) {
+		t.Errorf("the cache was saved right away: %v", err)
+	}
+	if err := cache.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The least recently used song is evicted, Queen was looked up first
+	cache.Put(Query{Artist: "Daft Punk", Title: "One More Time"}, &Lyrics{Text: "One more time"})
+	if _, ok := cache.Get(queen); ok {
+		t.Error("the least recently used song was kept")
+	}
+
+	// Only the flushed state is on disk
+	loaded, err := NewCache(dir, 2, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := loaded.Get(Query{Identifier: "youtube:fJ9rUzIMcZQ"}); !ok || got.Text != found.Text {
+		t.Errorf("got %+v, %v after loading", got, ok)
+	}
+	if _, ok := loaded.Get(Query{Artist: "Daft Punk", Title: "One More Time"}); ok {
+		t.Error("a song cached after the flush was saved")
+	}
+	if err := cache.Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSameArtist(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Queen", "queen", true},
+		{"BTS", "BTS (방탄소년단)", true},
+		{"Calvin Harris & Dua Lipa", "Calvin Harris", true},
+		{"Rick Astley", "Some Band", false},
+		{"Bea", "Beatles", false},
+		{"", "", true},
+		{"Queen", "", false},
+	}
+	for _, test := range tests {
+		if got := SameArtist(test.a, test.b); got != test.want {
+			t.Errorf("SameArtist(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
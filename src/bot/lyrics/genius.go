@@ -0,0 +1,155 @@
+package lyrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Genius searches the Genius API and reads the lyrics from the song's page,
+// since the API does not return them.
+type Genius struct {
+	client *http.Client
+	token  string
+	// APIURL is the base of the API, replaced in tests.
+	APIURL string
+}
+
+func NewGenius(client *http.Client, token string) *Genius {
+	return &Genius{client: client, token: token, APIURL: "https://api.genius.com"}
+}
+
+func (g *Genius) Name() string {
+	return "Genius"
+}
+
+// GeniusHit is a song found by a Genius search.
+type GeniusHit struct {
+	Title      string
+	Artist     string
+	URL        string
+	ArtworkURL string
+}
+
+type geniusSearch struct {
+	Response struct {
+		Hits []struct {
+			Result struct {
+				URL           string `json:"url"`
+				Title         string `json:"title"`
+				ArtworkURL    string `json:"song_art_image_thumbnail_url"`
+				PrimaryArtist struct {
+					Name string `json:"name"`
+				} `json:"primary_artist"`
+			} `json:"result"`
+		} `json:"hits"`
+	} `json:"response"`
+}
+
+// Search returns the songs matching a query, best first.
+func (g *Genius) Search(ctx context.Context, query string) ([]GeniusHit, error) {
+	if g.token == "" {
+		return nil, ErrNotFound
+	}
+	header := http.Header{"Authorization": {"Bearer " + g.token}}
+	body, err := get(ctx, g.client, g.APIURL+"/search?q="+url.QueryEscape(query), header)
+	if err != nil {
+		return nil, err
+	}
+
+	var search geniusSearch
+	if err := json.Unmarshal(body, &search); err != nil {
+		return nil, err
+	}
+	hits := make([]GeniusHit, 0, len(search.Response.Hits))
+	for _, hit := range search.Response.Hits {
+		hits = append(hits, GeniusHit{
+			Title:      hit.Result.Title,
+			Artist:     hit.Result.PrimaryArtist.Name,
+			URL:        hit.Result.URL,
+			ArtworkURL: hit.Result.ArtworkURL,
+		})
+	}
+	return hits, nil
+}
+
+func (g *Genius) Lyrics(ctx context.Context, query Query) (*Lyrics, error) {
+	hits, err := g.Search(ctx, strings.TrimSpace(query.Artist+" "+query.Title))
+	if err != nil {
+		return nil, err
+	}
+	// Without the artist the title alone may still find the song
+	if len(hits) == 0 && query.Artist != "" {
+		if hits, err = g.Search(ctx, query.Title); err != nil {
+			return nil, err
+		}
+	}
+	if len(hits) == 0 {
+		return nil, ErrNotFound
+	}
+	return g.Page(ctx, bestHit(hits, query.Artist))
+}
+
+// bestHit prefers the first hit by the query's artist over the top hit,
+// which may be a better known song with the same title.
+func bestHit(hits []GeniusHit, artist string) GeniusHit {
+	if artist != "" {
+		for _, hit := range hits {
+			if SameArtist(artist, hit.Artist) {
+				return hit
+			}
+		}
+	}
+	return hits[0]
+}
+
+// Page reads the lyrics of a search hit from its page.
+func (g *Genius) Page(ctx context.Context, hit GeniusHit) (*Lyrics, error) {
+	// Genius blocks requests that don't look like they come from a browser
+	header := http.Header{"User-Agent": {"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"}}
+	body, err := get(ctx, g.client, hit.URL, header)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := parseGeniusPage(string(body))
+	if err != nil {
+		return nil, err
+	}
+	return &Lyrics{
+		Artist:     hit.Artist,
+		Title:      hit.Title,
+		Text:       text,
+		Source:     g.Name(),
+		URL:        hit.URL,
+		ArtworkURL: hit.ArtworkURL,
+	}, nil
+}
+
+// parseGeniusPage extracts the lyrics from a song page, where they are split
+// over several containers with <br> line breaks.
+func parseGeniusPage(page string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	doc.Find("div[data-lyrics-container='true']").Each(func(i int, s *goquery.Selection) {
+		s.Find("br").ReplaceWithHtml("\n")
+		// Headers and annotations that are not part of the lyrics
+		s.Find("[data-exclude-from-selection='true']").Remove()
+		sb.WriteString(s.Text() + "\n")
+	})
+
+	text := strings.TrimSpace(sb.String())
+	if text == "" {
+		return "", fmt.Errorf("could not parse lyrics from page")
+	}
+	return text, nil
+}
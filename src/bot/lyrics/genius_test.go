@@ -0,0 +1,145 @@
+package lyrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestGenius(t *testing.T) {
+	var searches []string
+	server := fixtureServer(t, func(r *http.Request) string {
+		switch r.URL.Path {
+		case "/search":
+			if got := r.Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("got Authorization %q", got)
+			}
+			query := r.URL.Query().Get("q")
+			searches = append(searches, query)
+			switch query {
+			case "Rick Astley Never Gonna Give You Up", "Some Band Never Gonna Give You Up",
+				"Unknown Artist Never Gonna Give You Up", "Never Gonna Give You Up":
+				return "genius_search.json"
+			}
+			return "genius_search_empty.json"
+		case "/Rick-astley-never-gonna-give-you-up-lyrics":
+			return "genius_song.html"
+		case "/Some-band-never-gonna-give-you-up-lyrics":
+			return "genius_song.html"
+		}
+		return ""
+	})
+	genius := NewGenius(server.Client(), "token")
+	genius.APIURL = server.URL
+
+	t.Run("search", func(t *testing.T) {
+		hits, err := genius.Search(context.Background(), "Rick Astley Never Gonna Give You Up")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hits) != 2 {
+			t.Fatalf("got %d hits", len(hits))
+		}
+		want := GeniusHit{
+			Title:      "Never Gonna Give You Up",
+			Artist:     "Rick Astley",
+			URL:        server.URL + "/Rick-astley-never-gonna-give-you-up-lyrics",
+			ArtworkURL: "https://images.genius.com/never-gonna.300x300x1.jpg",
+		}
+		if hits[0] != want {
+			t.Errorf("got %+v, want %+v", hits[0], want)
+		}
+	})
+
+	t.Run("lyrics", func(t *testing.T) {
+		found, err := genius.Lyrics(context.Background(), Query{Artist: "Rick Astley", Title: "Never Gonna Give You Up"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Artist != "Rick Astley" || found.Source != "Genius" || found.Synced() {
+			t.Errorf("got %+v", found)
+		}
+		if found.ArtworkURL != "https://images.genius.com/never-gonna.300x300x1.jpg" {
+			t.Errorf("got artwork %q", found.ArtworkURL)
+		}
+	})
+
+	t.Run("hit by the artist", func(t *testing.T) {
+		// The top hit is by Rick Astley, the cover is further down
+		found, err := genius.Lyrics(context.Background(), Query{Artist: "Some Band", Title: "Never Gonna Give You Up"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Artist != "Some Band" || found.URL != server.URL+"/Some-band-never-gonna-give-you-up-lyrics" {
+			t.Errorf("got %+v", found)
+		}
+	})
+
+	t.Run("top hit without a match", func(t *testing.T) {
+		found, err := genius.Lyrics(context.Background(), Query{Artist: "Unknown Artist", Title: "Never Gonna Give You Up"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Artist != "Rick Astley" {
+			t.Errorf("got %+v", found)
+		}
+	})
+
+	t.Run("title only fallback", func(t *testing.T) {
+		searches = nil
+		found, err := genius.Lyrics(context.Background(), Query{Artist: "RickAstleyVEVO", Title: "Never Gonna Give You Up"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Title != "Never Gonna Give You Up" || len(searches) != 2 {
+			t.Errorf("got %q after searches %q", found.Title, searches)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := genius.Lyrics(context.Background(), Query{Artist: "Nobody", Title: "Nothing"})
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("got error %v", err)
+		}
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		_, err := NewGenius(server.Client(), "").Lyrics(context.Background(), Query{Title: "Never Gonna Give You Up"})
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("got error %v", err)
+		}
+	})
+}
+
+func TestParseGeniusPage(t *testing.T) {
+	page, err := os.ReadFile("testdata/genius_song.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseGeniusPage(string(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[Verse 1]\n" +
+		"We're no strangers to love\n" +
+		"You know the rules and so do I\n" +
+		"\n" +
+		"[Chorus]\n" +
+		"Never gonna give you up\n" +
+		"Never gonna let you down\n" +
+		"Never gonna run around and desert you\n" +
+		"Never gonna make you cry"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	empty, err := os.ReadFile("testdata/genius_no_lyrics.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseGeniusPage(string(empty)); err == nil {
+		t.Error("parsed lyrics from a page without any")
+	}
+}
@@ -0,0 +1,75 @@
+package lyrics
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// LavaLyrics asks the Lavalink node through the LavaLyrics plugin, which
+// gets lyrics from the track's own source or from the plugins it knows.
+// Nodes without the plugin answer with a 404, reported as ErrNotFound.
+type LavaLyrics struct {
+	// rest sends requests to the node, adding its address and password.
+	rest doer
+}
+
+func NewLavaLyrics(rest doer) *LavaLyrics {
+	return &LavaLyrics{rest: rest}
+}
+
+func (l *LavaLyrics) Name() string {
+	return "LavaLyrics"
+}
+
+type lavaLyricsResponse struct {
+	SourceName string `json:"sourceName"`
+	Provider   string `json:"provider"`
+	Text       string `json:"text"`
+	Lines      []struct {
+		Timestamp int64  `json:"timestamp"`
+		Line      string `json:"line"`
+	} `json:"lines"`
+}
+
+func (l *LavaLyrics) Lyrics(ctx context.Context, query Query) (*Lyrics, error) {
+	if query.Track == "" {
+		return nil, ErrNotFound
+	}
+	// The REST client replaces the scheme and host with the node's
+	body, err := get(ctx, l.rest, "http://lavalink/v4/lyrics?track="+url.QueryEscape(query.Track), nil)
+	if err != nil {
+		return nil, err
+	}
+	// The plugin answers with no content when it found nothing
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var response lavaLyricsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	lyrics := &Lyrics{
+		Artist: query.Artist,
+		Title:  query.Title,
+		Text:   strings.TrimSpace(response.Text),
+		Source: cmp.Or(response.Provider, response.SourceName, l.Name()),
+	}
+	for _, line := range response.Lines {
+		lyrics.Lines = append(lyrics.Lines, Line{
+			Start: time.Duration(line.Timestamp) * time.Millisecond,
+			Text:  strings.TrimSpace(line.Line),
+		})
+	}
+	if lyrics.Text == "" {
+		lyrics.Text = linesText(lyrics.Lines)
+	}
+	if lyrics.Text == "" {
+		return nil, ErrNotFound
+	}
+	return lyrics, nil
+}
@@ -0,0 +1,83 @@
+package lyrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeNode answers like a Lavalink node's REST client.
+type fakeNode struct {
+	status   int
+	body     string
+	requests []string
+}
+
+func (n *fakeNode) Do(req *http.Request) (*http.Response, error) {
+	n.requests = append(n.requests, req.URL.String())
+	return &http.Response{
+		StatusCode: n.status,
+		Body:       io.NopCloser(strings.NewReader(n.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestLavaLyrics(t *testing.T) {
+	query := Query{Artist: "Queen", Title: "Bohemian Rhapsody", Track: "QAAAjQIAJVJpY2sgQXN0bGV5"}
+
+	t.Run("synced", func(t *testing.T) {
+		node := &fakeNode{status: http.StatusOK, body: `{"sourceName":"youtube","provider":"Musixmatch","text":null,"lines":[{"timestamp":0,"duration":1500,"line":"Is this the real life?","plugin":{}},{"timestamp":1500,"duration":2000,"line":"Is this just fantasy?","plugin":{}}],"plugin":{}}`}
+		found, err := NewLavaLyrics(node).Lyrics(context.Background(), query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "http://lavalink/v4/lyrics?track=QAAAjQIAJVJpY2sgQXN0bGV5"; len(node.requests) != 1 || node.requests[0] != want {
+			t.Errorf("got requests %q", node.requests)
+		}
+		if found.Source != "Musixmatch" || found.Artist != "Queen" || len(found.Lines) != 2 || found.Lines[1].Start != 1500*time.Millisecond {
+			t.Errorf("got %+v", found)
+		}
+		if found.Text != "Is this the real life?\nIs this just fantasy?" {
+			t.Errorf("got text %q", found.Text)
+		}
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		node := &fakeNode{status: http.StatusOK, body: `{"sourceName":"deezer","provider":"","text":"Mama, just killed a man\n","lines":null,"plugin":{}}`}
+		found, err := NewLavaLyrics(node).Lyrics(context.Background(), query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Synced() || found.Text != "Mama, just killed a man" || found.Source != "deezer" {
+			t.Errorf("got %+v", found)
+		}
+	})
+
+	for _, status := range []int{http.StatusNoContent, http.StatusNotFound} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			_, err := NewLavaLyrics(&fakeNode{status: status}).Lyrics(context.Background(), query)
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("got error %v", err)
+			}
+		})
+	}
+
+	t.Run("server error", func(t *testing.T) {
+		_, err := NewLavaLyrics(&fakeNode{status: http.StatusInternalServerError}).Lyrics(context.Background(), query)
+		if err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("got error %v", err)
+		}
+	})
+
+	t.Run("no track", func(t *testing.T) {
+		node := &fakeNode{status: http.StatusOK}
+		_, err := NewLavaLyrics(node).Lyrics(context.Background(), Query{Title: "Bohemian Rhapsody"})
+		if !errors.Is(err, ErrNotFound) || len(node.requests) != 0 {
+			t.Errorf("got error %v after %d requests", err, len(node.requests))
+		}
+	})
+}
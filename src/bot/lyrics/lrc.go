@@ -0,0 +1,62 @@
+package lyrics
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lrcTimestamp matches the [mm:ss.xx] tags of an LRC line. A line may have
+// several when it is repeated.
+var lrcTimestamp = regexp.MustCompile(`\[(\d+):(\d{1,2})(?:[.:](\d{1,3}))?\]`)
+
+// ParseLRC reads LRC synced lyrics, ignoring metadata tags such as [ar:...].
+// Lines are sorted by time.
+func ParseLRC(lrc string) []Line {
+	var lines []Line
+	for _, raw := range strings.Split(lrc, "\n") {
+		raw = strings.TrimSpace(raw)
+		matches := lrcTimestamp.FindAllStringSubmatchIndex(raw, -1)
+		if len(matches) == 0 || matches[0][0] != 0 {
+			continue
+		}
+
+		// The text follows the last of the leading tags
+		end := 0
+		var starts []time.Duration
+		for _, match := range matches {
+			if match[0] != end {
+				break
+			}
+			end = match[1]
+			starts = append(starts, lrcTime(raw, match))
+		}
+		text := strings.TrimSpace(raw[end:])
+		for _, start := range starts {
+			lines = append(lines, Line{Start: start, Text: text})
+		}
+	}
+	slices.SortStableFunc(lines, func(a, b Line) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	return lines
+}
+
+func lrcTime(s string, match []int) time.Duration {
+	minutes, _ := strconv.Atoi(s[match[2]:match[3]])
+	seconds, _ := strconv.Atoi(s[match[4]:match[5]])
+	d := time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	if match[6] >= 0 {
+		fraction := s[match[6]:match[7]]
+		value, _ := strconv.Atoi(fraction)
+		// .5 is half a second, .50 and .500 as well
+		for i := len(fraction); i < 3; i++ {
+			value *= 10
+		}
+		d += time.Duration(value) * time.Millisecond
+	}
+	return d
+}
@@ -0,0 +1,59 @@
+package lyrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLRC(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name string
+		lrc  string
+		want []Line
+	}{
+		{
+			name: "simple",
+			lrc:  "[00:01.00]First\n[00:02.50]Second",
+			want: []Line{{1000 * ms, "First"}, {2500 * ms, "Second"}},
+		},
+		{
+			name: "fractions",
+			lrc:  "[00:01.5]Tenths\n[00:02.50]Hundredths\n[00:03.500]Thousandths\n[00:04]None\n[00:05:25]Colon",
+			want: []Line{{1500 * ms, "Tenths"}, {2500 * ms, "Hundredths"}, {3500 * ms, "Thousandths"}, {4000 * ms, "None"}, {5250 * ms, "Colon"}},
+		},
+		{
+			name: "several timestamps on one line",
+			lrc:  "[00:10.00][01:10.00]Chorus\n[00:20.00]Verse",
+			want: []Line{{10000 * ms, "Chorus"}, {20000 * ms, "Verse"}, {70000 * ms, "Chorus"}},
+		},
+		{
+			name: "metadata tags",
+			lrc:  "[ar:Queen]\n[ti:Bohemian Rhapsody]\n[al:A Night at the Opera]\n[length: 05:55]\n[offset:+0]\n[00:01.00]Is this the real life?",
+			want: []Line{{1000 * ms, "Is this the real life?"}},
+		},
+		{
+			name: "blank lines and instrumental breaks",
+			lrc:  "\n[00:01.00]Sung\r\n[00:05.00]\n\nnot a line\n[01:02.03] spaced ",
+			want: []Line{{1000 * ms, "Sung"}, {5000 * ms, ""}, {62030 * ms, "spaced"}},
+		},
+		{
+			name: "long songs",
+			lrc:  "[75:00.00]Late",
+			want: []Line{{75 * time.Minute, "Late"}},
+		},
+		{
+			name: "empty",
+			lrc:  "",
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ParseLRC(test.lrc); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package lyrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// LRCLIB uses the public lrclib.net API, which has synced lyrics for many
+// songs and needs no credentials.
+type LRCLIB struct {
+	client *http.Client
+	// APIURL is the base of the API, replaced in tests.
+	APIURL string
+}
+
+func NewLRCLIB(client *http.Client) *LRCLIB {
+	return &LRCLIB{client: client, APIURL: "https://lrclib.net/api"}
+}
+
+func (l *LRCLIB) Name() string {
+	return "LRCLIB"
+}
+
+// LRCLIBRecord is a song as stored on LRCLIB.
+type LRCLIBRecord struct {
+	ID           int     `json:"id"`
+	TrackName    string  `json:"trackName"`
+	ArtistName   string  `json:"artistName"`
+	Duration     float64 `json:"duration"`
+	Instrumental bool    `json:"instrumental"`
+	PlainLyrics  string  `json:"plainLyrics"`
+	SyncedLyrics string  `json:"syncedLyrics"`
+}
+
+func (l *LRCLIB) Lyrics(ctx context.Context, query Query) (*Lyrics, error) {
+	// The exact lookup needs the artist, the search works with the title only
+	if query.Artist != "" {
+		params := url.Values{"artist_name": {query.Artist}, "track_name": {query.Title}}
+		if query.Duration > 0 {
+			params.Set("duration", fmt.Sprint(int(query.Duration.Seconds())))
+		}
+		var record LRCLIBRecord
+		err := l.getJSON(ctx, "/get?"+params.Encode(), &record)
+		if err == nil {
+			if lyrics := l.RecordLyrics(record); lyrics != nil {
+				return lyrics, nil
+			}
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	records, err := l.Search(ctx, query.Artist, query.Title)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if lyrics := l.RecordLyrics(record); lyrics != nil {
+			return lyrics, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Search returns the songs matching an artist and title, best first.
+func (l *LRCLIB) Search(ctx context.Context, artist string, title string) ([]LRCLIBRecord, error) {
+	params := url.Values{"track_name": {title}}
+	if artist != "" {
+		params.Set("artist_name", artist)
+	}
+	var records []LRCLIBRecord
+	if err := l.getJSON(ctx, "/search?"+params.Encode(), &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// RecordLyrics converts a record, or returns nil when it has no lyrics.
+func (l *LRCLIB) RecordLyrics(record LRCLIBRecord) *Lyrics {
+	lyrics := &Lyrics{
+		Artist: record.ArtistName,
+		Title:  record.TrackName,
+		Text:   strings.TrimSpace(record.PlainLyrics),
+		Lines:  ParseLRC(record.SyncedLyrics),
+		Source: l.Name(),
+		URL:    "https://lrclib.net",
+	}
+	if lyrics.Text == "" {
+		lyrics.Text = linesText(lyrics.Lines)
+	}
+	if lyrics.Text == "" {
+		return nil
+	}
+	return lyrics
+}
+
+func (l *LRCLIB) getJSON(ctx context.Context, path string, v any) error {
+	body, err := get(ctx, l.client, l.APIURL+path, nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,86 @@
+package lyrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLRCLIB(t *testing.T) {
+	server := fixtureServer(t, func(r *http.Request) string {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/get":
+			switch query.Get("artist_name") + " - " + query.Get("track_name") {
+			case "Borislav Slavov - I Want to Live":
+				if query.Get("duration") != "233" {
+					t.Errorf("got duration %q", query.Get("duration"))
+				}
+				return "lrclib_get_synced.json"
+			case "Ludwig van Beethoven - Moonlight Sonata":
+				return "lrclib_get_instrumental.json"
+			}
+		case "/search":
+			if query.Get("track_name") == "Moonlight Sonata" {
+				return "lrclib_search.json"
+			}
+			return "lrclib_search_empty.json"
+		}
+		return ""
+	})
+	lrclib := NewLRCLIB(server.Client())
+	lrclib.APIURL = server.URL
+
+	t.Run("synced", func(t *testing.T) {
+		found, err := lrclib.Lyrics(context.Background(), Query{
+			Artist:   "Borislav Slavov",
+			Title:    "I Want to Live",
+			Duration: 233 * time.Second,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found.Synced() || len(found.Lines) != 3 {
+			t.Fatalf("got lines %+v", found.Lines)
+		}
+		if found.Lines[2].Start != 23800*time.Millisecond || found.Lines[2].Text != "A soft caress as cold as death" {
+			t.Errorf("got line %+v", found.Lines[2])
+		}
+		if found.Text != "I feel your breath upon my neck\n\nA soft caress as cold as death" || found.Source != "LRCLIB" {
+			t.Errorf("got %+v", found)
+		}
+	})
+
+	t.Run("instrumental falls back to the search", func(t *testing.T) {
+		found, err := lrclib.Lyrics(context.Background(), Query{Artist: "Ludwig van Beethoven", Title: "Moonlight Sonata"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The instrumental record has no lyrics, the plain-only one is used
+		if found.Artist != "Some Choir" || found.Synced() || found.Text != "Moonlight on the water\nSilver on the sea" {
+			t.Errorf("got %+v", found)
+		}
+	})
+
+	t.Run("title only", func(t *testing.T) {
+		found, err := lrclib.Lyrics(context.Background(), Query{Title: "Moonlight Sonata"})
+		if err != nil || found.Artist != "Some Choir" {
+			t.Errorf("got %+v, %v", found, err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := lrclib.Lyrics(context.Background(), Query{Artist: "Nobody", Title: "Nothing"})
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("got error %v", err)
+		}
+	})
+
+	t.Run("record without lyrics", func(t *testing.T) {
+		if found := lrclib.RecordLyrics(LRCLIBRecord{TrackName: "Silence", Instrumental: true}); found != nil {
+			t.Errorf("got %+v", found)
+		}
+	})
+}
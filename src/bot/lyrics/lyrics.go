@@ -0,0 +1,126 @@
+// Package lyrics looks up the lyrics of a song from several providers, tried
+// in order until one has them.
+package lyrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a provider has no lyrics for a song.
+var ErrNotFound = errors.New("lyrics not found")
+
+// Query describes the song to find lyrics for. Providers use what they can.
+type Query struct {
+	Artist   string
+	Title    string
+	Duration time.Duration
+	ISRC     string
+	// Identifier is the source and identifier of the track the song was
+	// played from, e.g. "youtube:dQw4w9WgXcQ".
+	Identifier string
+	// Track is the encoded Lavalink track, for providers running on the node.
+	Track string
+}
+
+// Line is a line of synced lyrics, sung from Start on.
+type Line struct {
+	Start time.Duration
+	Text  string
+}
+
+type Lyrics struct {
+	Artist string
+	Title  string
+	Text   string
+	// Lines are set when the lyrics are synced to the song.
+	Lines []Line
+	// Source names the provider and URL links to the lyrics on its site,
+	// for attribution.
+	Source string
+	URL    string
+	// ArtworkURL is the song's cover art, if the provider knows it.
+	ArtworkURL string
+}
+
+// Synced reports whether the lyrics have timestamps.
+func (l *Lyrics) Synced() bool {
+	return len(l.Lines) > 0
+}
+
+type Provider interface {
+	Name() string
+	Lyrics(ctx context.Context, query Query) (*Lyrics, error)
+}
+
+// Providers is tried in order, the first one finding lyrics wins.
+type Providers []Provider
+
+// Lyrics returns the lyrics of the first provider that has them. Errors of
+// the other providers are only returned when none found any.
+func (p Providers) Lyrics(ctx context.Context, query Query) (*Lyrics, error) {
+	var errs []error
+	for _, provider := range p {
+		lyrics, err := provider.Lyrics(ctx, query)
+		if err == nil {
+			return lyrics, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return nil, ErrNotFound
+}
+
+// doer sends requests, like http.Client or a Lavalink node's REST client.
+type doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// get performs a GET request and returns the body. A 404 is reported as
+// ErrNotFound, any other status than 200 as an error.
+func get(ctx context.Context, client doer, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	// Lavalink answers 204 when a track has no lyrics
+	case http.StatusNotFound, http.StatusNoContent:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+}
+
+// linesText joins synced lines into plain lyrics.
+func linesText(lines []Line) string {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.Text
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n"))
+}
@@ -0,0 +1,97 @@
+package lyrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixtureServer answers every request with the testdata file route picks
+// for it, or a 404 when it picks none. "{{server}}" in a fixture is replaced
+// by the server's URL.
+func fixtureServer(t *testing.T, route func(r *http.Request) string) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := route(r)
+		if name == "" {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(strings.ReplaceAll(string(data), "{{server}}", server.URL)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+type fakeProvider struct {
+	name   string
+	lyrics *Lyrics
+	err    error
+	called bool
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) Lyrics(ctx context.Context, query Query) (*Lyrics, error) {
+	p.called = true
+	return p.lyrics, p.err
+}
+
+func TestProviders(t *testing.T) {
+	found := &Lyrics{Text: "la la la", Source: "second"}
+
+	t.Run("first with lyrics wins", func(t *testing.T) {
+		first := &fakeProvider{name: "first", err: ErrNotFound}
+		second := &fakeProvider{name: "second", lyrics: found}
+		third := &fakeProvider{name: "third", lyrics: &Lyrics{Text: "other"}}
+		got, err := Providers{first, second, third}.Lyrics(context.Background(), Query{Title: "song"})
+		if err != nil || got != found {
+			t.Fatalf("got %v, %v", got, err)
+		}
+		if !first.called || third.called {
+			t.Errorf("providers called: first %v, third %v", first.called, third.called)
+		}
+	})
+
+	t.Run("errors are kept when nothing is found", func(t *testing.T) {
+		failure := errors.New("boom")
+		_, err := Providers{
+			&fakeProvider{name: "broken", err: failure},
+			&fakeProvider{name: "empty", err: ErrNotFound},
+		}.Lyrics(context.Background(), Query{Title: "song"})
+		if !errors.Is(err, failure) || !strings.Contains(err.Error(), "broken") {
+			t.Errorf("got error %v", err)
+		}
+	})
+
+	t.Run("errors are dropped when a provider finds lyrics", func(t *testing.T) {
+		got, err := Providers{
+			&fakeProvider{name: "broken", err: errors.New("boom")},
+			&fakeProvider{name: "second", lyrics: found},
+		}.Lyrics(context.Background(), Query{Title: "song"})
+		if err != nil || got != found {
+			t.Errorf("got %v, %v", got, err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := Providers{&fakeProvider{name: "empty", err: ErrNotFound}}.Lyrics(context.Background(), Query{Title: "song"})
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("got error %v", err)
+		}
+	})
+}
@@ -0,0 +1,332 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/log"
+	"github.com/disgoorg/snowflake/v2"
+
+	"jukeboxitus/src/bot/lyrics"
+	"jukeboxitus/src/bot/songtitle"
+)
+
+var lyricsClient = &http.Client{Timeout: 10 * time.Second}
+
+// lyricsTimeout bounds a whole /lyrics lookup, over every provider.
+const lyricsTimeout = 30 * time.Second
+
+const (
+	// liveLyricsTick is how often live lyrics check the player's position.
+	liveLyricsTick = 500 * time.Millisecond
+	// liveLyricsMinEdit keeps live lyrics within Discord's rate limits for
+	// message edits.
+	liveLyricsMinEdit = 2 * time.Second
+	// liveLyricsContext is how many lines live lyrics show before and after
+	// the current one.
+	liveLyricsContext = 2
+)
+
+// lyricsProviders returns the configured lyrics providers in order.
+// LavaLyrics is skipped while no node is connected.
+func (b *Bot) lyricsProviders() lyrics.Providers {
+	config := b.Config()
+	var providers lyrics.Providers
+	for _, name := range config.LyricsProviders() {
+		switch name {
+		case "genius":
+			providers = append(providers, lyrics.NewGenius(lyricsClient, config.GeniusToken))
+		case "lrclib":
+			providers = append(providers, lyrics.NewLRCLIB(lyricsClient))
+		case "lavalyrics":
+			if node := b.Lavalink.BestNode(); node != nil {
+				providers = append(providers, lyrics.NewLavaLyrics(node.Rest()))
+			}
+		}
+	}
+	return providers
+}
+
+func (b *Bot) Lyrics(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	var (
+		query   lyrics.Query
+		artwork string
+	)
+
+	// 1. Extract optional arguments from the interaction
+	options := optionMap(data.Options)
+	if opt, ok := options["artist"]; ok {
+		query.Artist = strings.TrimSpace(opt.StringValue())
+	}
+	if opt, ok := options["title"]; ok {
+		query.Title = strings.TrimSpace(opt.StringValue())
+		// A title alone may be "Artist - Song"
+		if query.Artist == "" {
+			song := songtitle.Parse(query.Title, "")
+			query.Artist, query.Title = song.Artist, song.Title
+		}
+	}
+	live := false
+	if opt, ok := options["live"]; ok {
+		live = opt.BoolValue()
+	}
+	position := 0
+	if opt, ok := options["position"]; ok {
+		position = int(opt.IntValue())
+	}
+
+	// 2. Fallback: If both are empty, use the queued track at position or
+	// the current player track. Live lyrics always follow the current track.
+	// The track is read once, it may end while the providers are asked
+	_, current := b.currentTrack(event.GuildID)
+	playing := current != nil
+	if live && (!playing || position > 0) {
+		return b.SendResponse(event.Interaction, "Lyrics Error",
+			fmt.Sprintf("%s Live lyrics follow the song that is playing, please start one and leave out the position.", IconError), ColorError)
+	}
+	switch {
+	case position > 0:
+		tracks := b.Queues.Get(event.GuildID).List()
+		if position > len(tracks) {
+			return b.SendResponse(event.Interaction, "Lyrics Error",
+				fmt.Sprintf("%s There is no song at position %d, the queue has %d.", IconError, position, len(tracks)), ColorError)
+		}
+		track := tracks[position-1]
+		query, artwork = trackLyricsQuery(track), trackArtwork(track)
+
+	case query.Artist == "" && query.Title == "":
+		if !playing {
+			return b.SendResponse(event.Interaction, "Lyrics Error",
+				fmt.Sprintf("%s No song is currently playing and no search terms provided.", IconError), ColorError)
+		}
+		query, artwork = trackLyricsQuery(*current), trackArtwork(*current)
+	}
+
+	// 3. Defer response (the providers may take a while)
+	b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+
+	// 4. Ask the cache, then the providers in order
+	ctx, cancel := interactionContext(event.Interaction, lyricsTimeout)
+	defer cancel()
+	found, certain, err := b.findLyrics(ctx, query)
+	if err != nil {
+		if !errors.Is(err, lyrics.ErrNotFound) {
+			log.Error("failed to look up lyrics: ", err)
+		}
+		return b.SendResponse(event.Interaction, "Lyrics Not Found",
+			fmt.Sprintf("%s Could not find lyrics for **%s %s**", IconSearch, query.Artist, query.Title), ColorWarning)
+	}
+	if live && found.Synced() {
+		if _, now := b.currentTrack(event.GuildID); now == nil || now.Encoded != current.Encoded {
+			return b.SendResponse(event.Interaction, "Lyrics Error",
+				fmt.Sprintf("%s The song ended while looking up its lyrics.", IconError), ColorError)
+		}
+		return b.startLiveLyrics(event, found, current.Encoded, cmp.Or(found.ArtworkURL, artwork))
+	}
+
+	// 5. When the song found is by someone else, let the user pick
+	if !certain && !live {
+		if matches := b.findLyricsMatches(ctx, query, found); len(matches) > 1 {
+			return b.offerLyricsMatches(event, query, artwork, matches)
+		}
+	}
+
+	// 6. Send the lyrics, over several pages if needed
+	lyricText := found.Text
+	if live {
+		lyricText = fmt.Sprintf("%s No synced lyrics were found, here are the plain ones.\n\n%s", IconWarning, lyricText)
+	}
+	return b.sendLyrics(event, lyricsHeader(found, query), lyricText, lyricsSource(found), cmp.Or(found.ArtworkURL, artwork))
+}
+
+// currentTrack returns a guild's player and the track it plays, read once so
+// the track can't end between checking and using it. Both are nil when the
+// bot is not in a voice channel.
+func (b *Bot) currentTrack(guildID string) (disgolink.Player, *lavalink.Track) {
+	player := b.Lavalink.ExistingPlayer(snowflake.MustParse(guildID))
+	if player == nil {
+		return nil, nil
+	}
+	return player, player.Track()
+}
+
+// findLyrics looks up the lyrics of a song in the cache, and asks the
+// providers when they are not cached. Songs without lyrics are cached too,
+// failed lookups are not. Lyrics by another artist than the query's are not
+// certain and not cached, since they may be another song with the same title.
+func (b *Bot) findLyrics(ctx context.Context, query lyrics.Query) (found *lyrics.Lyrics, certain bool, err error) {
+	if found, ok := b.LyricsCache.Get(query); ok {
+		if found == nil {
+			return nil, true, lyrics.ErrNotFound
+		}
+		return found, true, nil
+	}
+
+	found, err = b.lyricsProviders().Lyrics(ctx, query)
+	if err != nil && !errors.Is(err, lyrics.ErrNotFound) {
+		return nil, false, err
+	}
+	if found != nil && query.Artist != "" && found.Artist != "" && !lyrics.SameArtist(query.Artist, found.Artist) {
+		return found, false, nil
+	}
+	b.LyricsCache.Put(query, found)
+	return found, true, err
+}
+
+// trackLyricsQuery describes the song a track plays, from its title, author
+// and ISRC.
+func trackLyricsQuery(track lavalink.Track) lyrics.Query {
+	info := trackInfo(track)
+	song := songtitle.Parse(info.Title, info.Author)
+	query := lyrics.Query{
+		Artist:   song.Artist,
+		Title:    song.Title,
+		Duration: time.Duration(info.Length.Milliseconds()) * time.Millisecond,
+		Track:    track.Encoded,
+	}
+	// Tracks loaded shortly before they play have no identifier yet
+	if info.Identifier != "" {
+		query.Identifier = info.SourceName + ":" + info.Identifier
+	}
+	if info.ISRC != nil {
+		query.ISRC = *info.ISRC
+	}
+	return query
+}
+
+func lyricsHeader(found *lyrics.Lyrics, query lyrics.Query) string {
+	title := cmp.Or(found.Title, query.Title)
+	if artist := cmp.Or(found.Artist, query.Artist); artist != "" {
+		return fmt.Sprintf("Lyrics: %s - %s", artist, title)
+	}
+	return fmt.Sprintf("Lyrics: %s", title)
+}
+
+// lyricsSource renders the provider of some lyrics, linked to them if it can.
+func lyricsSource(found *lyrics.Lyrics) string {
+	if found.URL == "" {
+		return found.Source
+	}
+	return fmt.Sprintf("[%s](<%s>)", found.Source, found.URL)
+}
+
+// startLiveLyrics shows synced lyrics and keeps the line being sung
+// highlighted while track plays. The position is read from the player on
+// every tick, so seeking moves the lyrics along.
+func (b *Bot) startLiveLyrics(event *discordgo.InteractionCreate, found *lyrics.Lyrics, track string, artwork string) error {
+	guildID := event.GuildID
+	line := -1
+	if player := b.Lavalink.ExistingPlayer(snowflake.MustParse(guildID)); player != nil {
+		line = currentLyricsLine(found.Lines, player.Position())
+	}
+	message, err := b.Session.InteractionResponseEdit(event.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{liveLyricsEmbed(found, artwork, line, "")},
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, done := b.liveLyrics.start(guildID)
+	go func() {
+		defer done()
+
+		edit := func(embed *discordgo.MessageEmbed) bool {
+			if _, err := b.Session.ChannelMessageEditEmbed(message.ChannelID, message.ID, embed); err != nil {
+				log.Debug("failed to update live lyrics: ", err)
+				return false
+			}
+			return true
+		}
+
+		ticker := time.NewTicker(liveLyricsTick)
+		defer ticker.Stop()
+		edited := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			// The track ended, was skipped or the bot left
+			player, playing := b.currentTrack(guildID)
+			if playing == nil || playing.Encoded != track {
+				edit(liveLyricsEmbed(found, artwork, line, "The song ended, live lyrics stopped."))
+				return
+			}
+			if player.Paused() {
+				edit(liveLyricsEmbed(found, artwork, line, "The song was paused, live lyrics stopped."))
+				return
+			}
+
+			// Only edit when the line changed, and not more often than the
+			// rate limits allow
+			current := currentLyricsLine(found.Lines, player.Position())
+			if current == line || time.Since(edited) < liveLyricsMinEdit {
+				continue
+			}
+			line = current
+			edited = time.Now()
+			if !edit(liveLyricsEmbed(found, artwork, line, "")) {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// currentLyricsLine returns the index of the line sung at position, or -1
+// before the first one.
+func currentLyricsLine(lines []lyrics.Line, position lavalink.Duration) int {
+	at := time.Duration(position) * time.Millisecond
+	return sort.Search(len(lines), func(i int) bool {
+		return lines[i].Start > at
+	}) - 1
+}
+
+// liveLyricsEmbed renders the current line of synced lyrics with a few lines
+// around it. A stopped view shows why it stopped.
+func liveLyricsEmbed(found *lyrics.Lyrics, artwork string, current int, stopped string) *discordgo.MessageEmbed {
+	var sb strings.Builder
+	first := max(current-liveLyricsContext, 0)
+	last := min(max(current, 0)+liveLyricsContext, len(found.Lines)-1)
+	if current < 0 {
+		sb.WriteString("**▶ ♪**\n")
+	}
+	for i := first; i <= last; i++ {
+		text := cmp.Or(found.Lines[i].Text, "♪")
+		if i == current {
+			fmt.Fprintf(&sb, "**▶ %s**\n", text)
+		} else {
+			fmt.Fprintf(&sb, "%s\n", text)
+		}
+	}
+	fmt.Fprintf(&sb, "\n*Source: %s*", lyricsSource(found))
+	if stopped != "" {
+		fmt.Fprintf(&sb, "\n%s %s", IconStop, stopped)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Live " + lyricsHeader(found, lyrics.Query{}),
+		Description: sb.String(),
+		Color:       ColorDefault,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Jukeboxitus Music",
+		},
+	}
+	if artwork != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: artwork}
+	}
+	return embed
+}
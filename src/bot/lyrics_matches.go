@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/log"
+
+	"jukeboxitus/src/bot/lyrics"
+)
+
+const (
+	// lyricsMatchLimit is how many songs each provider offers when the
+	// lyrics found look like another song.
+	lyricsMatchLimit = 5
+	lyricsMatchTTL   = 10 * time.Minute
+)
+
+// lyricsMatches keeps the songs offered by /lyrics when it is unsure which
+// one was meant, until someone picks one.
+type lyricsMatches struct {
+	mu     sync.Mutex
+	offers map[string]lyricsOffer
+}
+
+type lyricsOffer struct {
+	query   lyrics.Query
+	artwork string
+	matches []lyricsMatch
+	expires time.Time
+}
+
+// lyricsMatch is a song offered to pick from. Genius hits are read from their
+// page once picked, the other matches come with their lyrics.
+type lyricsMatch struct {
+	artist string
+	title  string
+	source string
+	found  *lyrics.Lyrics
+	genius *lyrics.GeniusHit
+}
+
+func (m *lyricsMatches) put(id string, offer lyricsOffer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.offers == nil {
+		m.offers = make(map[string]lyricsOffer)
+	}
+	now := time.Now()
+	for key, offer := range m.offers {
+		if now.After(offer.expires) {
+			delete(m.offers, key)
+		}
+	}
+	offer.expires = now.Add(lyricsMatchTTL)
+	m.offers[id] = offer
+}
+
+func (m *lyricsMatches) get(id string) (lyricsOffer, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	offer, ok := m.offers[id]
+	if !ok || time.Now().After(offer.expires) {
+		return lyricsOffer{}, false
+	}
+	return offer, true
+}
+
+// findLyricsMatches searches Genius and LRCLIB, when they are configured, for
+// the songs a query may mean. The lyrics found first come first.
+func (b *Bot) findLyricsMatches(ctx context.Context, query lyrics.Query, found *lyrics.Lyrics) []lyricsMatch {
+	var (
+		matches []lyricsMatch
+		seen    = make(map[string]bool)
+	)
+	add := func(match lyricsMatch) {
+		key := strings.ToLower(match.artist + "|" + match.title)
+		// A select menu holds 25 options
+		if seen[key] || len(matches) == 25 {
+			return
+		}
+		seen[key] = true
+		matches = append(matches, match)
+	}
+	add(lyricsMatch{artist: found.Artist, title: found.Title, source: found.Source, found: found})
+
+	config := b.Config()
+	for _, name := range config.LyricsProviders() {
+		switch name {
+		case "genius":
+			genius := lyrics.NewGenius(lyricsClient, config.GeniusToken)
+			hits, err := genius.Search(ctx, strings.TrimSpace(query.Artist+" "+query.Title))
+			if err != nil && !errors.Is(err, lyrics.ErrNotFound) {
+				log.Debug("failed to search Genius: ", err)
+			}
+			for _, hit := range hits[:min(len(hits), lyricsMatchLimit)] {
+				add(lyricsMatch{artist: hit.Artist, title: hit.Title, source: genius.Name(), genius: &hit})
+			}
+
+		case "lrclib":
+			// The artist is left out, it may be what was wrong
+			lrclib := lyrics.NewLRCLIB(lyricsClient)
+			records, err := lrclib.Search(ctx, "", query.Title)
+			if err != nil && !errors.Is(err, lyrics.ErrNotFound) {
+				log.Debug("failed to search LRCLIB: ", err)
+			}
+			offered := 0
+			for _, record := range records {
+				if offered == lyricsMatchLimit {
+					break
+				}
+				if found := lrclib.RecordLyrics(record); found != nil {
+					add(lyricsMatch{artist: found.Artist, title: found.Title, source: found.Source, found: found})
+					offered++
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// offerLyricsMatches answers a deferred /lyrics with a select menu of the
+// songs it may have meant.
+func (b *Bot) offerLyricsMatches(event *discordgo.InteractionCreate, query lyrics.Query, artwork string, matches []lyricsMatch) error {
+	b.lyricsMatches.put(event.ID, lyricsOffer{query: query, artwork: artwork, matches: matches})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s The best match is **%s - %s**, which doesn't look like it's by **%s**. Pick the song you meant:\n\n",
+		IconSearch, matches[0].artist, matches[0].title, query.Artist)
+	menuOptions := make([]discordgo.SelectMenuOption, 0, len(matches))
+	for i, match := range matches {
+		fmt.Fprintf(&sb, "**%d.** %s - %s *(%s)*\n", i+1, match.artist, match.title, match.source)
+		menuOptions = append(menuOptions, discordgo.SelectMenuOption{
+			Label:       truncate(fmt.Sprintf("%d. %s", i+1, match.title), 100),
+			Description: truncate(fmt.Sprintf("%s • %s", match.artist, match.source), 100),
+			Value:       strconv.Itoa(i),
+		})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Which Song?",
+		Description: sb.String(),
+		Color:       ColorWarning,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Jukeboxitus Music",
+		},
+	}
+	_, err := b.Session.InteractionResponseEdit(event.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+		Components: &[]discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    "lyricspick:" + event.ID,
+					Placeholder: "Pick a song",
+					Options:     menuOptions,
+				},
+			}},
+		},
+	})
+	return err
+}
+
+// LyricsPick shows the lyrics of the song picked from the menu of
+// offerLyricsMatches, and remembers the pick for the song that was looked up.
+func (b *Bot) LyricsPick(event *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) error {
+	offer, ok := b.lyricsMatches.get(strings.TrimPrefix(data.CustomID, "lyricspick:"))
+	if !ok {
+		return b.UpdateResponse(event.Interaction, "Lyrics Expired",
+			fmt.Sprintf("%s These songs have expired, please run /lyrics again.", IconEmpty), "", ColorWarning)
+	}
+	index, err := strconv.Atoi(data.Values[0])
+	if err != nil || index < 0 || index >= len(offer.matches) {
+		return fmt.Errorf("invalid lyrics selection %q", data.Values[0])
+	}
+	match := offer.matches[index]
+
+	// Reading a Genius page may take a while
+	if err := b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	}); err != nil {
+		return err
+	}
+
+	found := match.found
+	if found == nil {
+		ctx, cancel := interactionContext(event.Interaction, lyricsTimeout)
+		defer cancel()
+		found, err = lyrics.NewGenius(lyricsClient, b.Config().GeniusToken).Page(ctx, *match.genius)
+		if err != nil {
+			log.Error("failed to read lyrics from Genius: ", err)
+			embed := &discordgo.MessageEmbed{
+				Title:       "Lyrics Error",
+				Description: fmt.Sprintf("%s Could not read the lyrics of **%s - %s**.", IconError, match.artist, match.title),
+				Color:       ColorError,
+				Footer: &discordgo.MessageEmbedFooter{
+					Text: "Jukeboxitus Music",
+				},
+			}
+			_, err = b.Session.InteractionResponseEdit(event.Interaction, &discordgo.WebhookEdit{
+				Embeds:     &[]*discordgo.MessageEmbed{embed},
+				Components: &[]discordgo.MessageComponent{},
+			})
+			return err
+		}
+	}
+
+	b.LyricsCache.Put(offer.query, found)
+	return b.sendLyrics(event, lyricsHeader(found, offer.query), found.Text, lyricsSource(found), cmp.Or(found.ArtworkURL, offer.artwork))
+}
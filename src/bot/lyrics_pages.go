@@ -0,0 +1,198 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// lyricsPageSize is the most characters a page of lyrics holds, well
+	// below the embed description limit.
+	lyricsPageSize = 3000
+	// lyricsMaxPages is the most pages shown with buttons, longer lyrics
+	// are sent as a text file.
+	lyricsMaxPages = 8
+	lyricsViewTTL  = 15 * time.Minute
+)
+
+// lyricsViews keeps the pages of the lyrics shown by /lyrics while their
+// buttons can be used.
+type lyricsViews struct {
+	mu    sync.Mutex
+	views map[string]lyricsView
+}
+
+type lyricsView struct {
+	title   string
+	pages   []string
+	source  string
+	artwork string
+	expires time.Time
+}
+
+func (v *lyricsViews) put(id string, view lyricsView) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.views == nil {
+		v.views = make(map[string]lyricsView)
+	}
+	now := time.Now()
+	for key, view := range v.views {
+		if now.After(view.expires) {
+			delete(v.views, key)
+		}
+	}
+	view.expires = now.Add(lyricsViewTTL)
+	v.views[id] = view
+}
+
+func (v *lyricsViews) get(id string) (lyricsView, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	view, ok := v.views[id]
+	if !ok || time.Now().After(view.expires) {
+		return lyricsView{}, false
+	}
+	return view, true
+}
+
+// sendLyrics answers a deferred /lyrics with the first page of the lyrics
+// and buttons for the others, or with a text file when they are very long.
+func (b *Bot) sendLyrics(event *discordgo.InteractionCreate, title string, text string, source string, artwork string) error {
+	pages := splitLyrics(text, lyricsPageSize)
+	if len(pages) > lyricsMaxPages {
+		embed := lyricsPageEmbed(lyricsView{
+			title:   title,
+			pages:   []string{fmt.Sprintf("%s These lyrics are too long for a message, they are attached as a file.", IconQueue)},
+			source:  source,
+			artwork: artwork,
+		}, 0)
+		_, err := b.Session.InteractionResponseEdit(event.Interaction, &discordgo.WebhookEdit{
+			Embeds: &[]*discordgo.MessageEmbed{embed},
+			Files: []*discordgo.File{{
+				Name:        "lyrics.txt",
+				ContentType: "text/plain",
+				Reader:      strings.NewReader(text + "\n"),
+			}},
+		})
+		return err
+	}
+
+	view := lyricsView{title: title, pages: pages, source: source, artwork: artwork}
+	components := []discordgo.MessageComponent{}
+	if len(pages) > 1 {
+		b.lyricsViews.put(event.ID, view)
+		components = pageButtons("lyrics:"+event.ID, 0, len(pages))
+	}
+	_, err := b.Session.InteractionResponseEdit(event.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &[]*discordgo.MessageEmbed{lyricsPageEmbed(view, 0)},
+		Components: &components,
+	})
+	return err
+}
+
+// LyricsPage handles the navigation buttons of /lyrics. Their custom ID is
+// "lyrics:<interaction>:<button>:<page>".
+func (b *Bot) LyricsPage(event *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) error {
+	parts := strings.Split(data.CustomID, ":")
+	if len(parts) != 4 {
+		return fmt.Errorf("invalid lyrics button %q", data.CustomID)
+	}
+	page, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return err
+	}
+
+	view, ok := b.lyricsViews.get(parts[1])
+	if !ok {
+		return b.UpdateResponse(event.Interaction, "Lyrics Expired",
+			fmt.Sprintf("%s These lyrics have expired, please run /lyrics again.", IconEmpty), "", ColorWarning)
+	}
+	page = min(max(page, 0), len(view.pages)-1)
+
+	return b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{lyricsPageEmbed(view, page)},
+			Components: pageButtons("lyrics:"+parts[1], page, len(view.pages)),
+		},
+	})
+}
+
+func lyricsPageEmbed(view lyricsView, page int) *discordgo.MessageEmbed {
+	footer := "Jukeboxitus Music"
+	if len(view.pages) > 1 {
+		footer = fmt.Sprintf("Page %d/%d • %s", page+1, len(view.pages), footer)
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       view.title,
+		Description: fmt.Sprintf("%s\n\n*Source: %s*", view.pages[page], view.source),
+		Color:       ColorDefault,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: footer,
+		},
+	}
+	if view.artwork != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: view.artwork}
+	}
+	return embed
+}
+
+// splitLyrics cuts lyrics into pages of at most size characters, between
+// stanzas where it can, otherwise between lines. Lines longer than a page are
+// cut between characters.
+func splitLyrics(text string, size int) []string {
+	var (
+		pages []string
+		page  strings.Builder
+		runes int
+	)
+	flush := func() {
+		if runes > 0 {
+			pages = append(pages, strings.TrimSpace(page.String()))
+			page.Reset()
+			runes = 0
+		}
+	}
+	add := func(part string, separator string) {
+		length := len([]rune(part))
+		if runes > 0 && runes+len([]rune(separator))+length > size {
+			flush()
+		}
+		if runes > 0 {
+			page.WriteString(separator)
+			runes += len([]rune(separator))
+		}
+		page.WriteString(part)
+		runes += length
+	}
+
+	for _, stanza := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if len([]rune(stanza)) <= size {
+			add(stanza, "\n\n")
+			continue
+		}
+		// The stanza alone is too long for a page
+		flush()
+		for _, line := range strings.Split(stanza, "\n") {
+			for chunk := []rune(line); len(chunk) > 0; {
+				n := min(len(chunk), size)
+				add(string(chunk[:n]), "\n")
+				chunk = chunk[n:]
+			}
+		}
+		flush()
+	}
+	flush()
+	if len(pages) == 0 {
+		return []string{""}
+	}
+	return pages
+}
@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxMessageLinks is the most links "Add to queue" loads from one message.
+const maxMessageLinks = 20
+
+// The report of the loaded links has to fit in the response with its header
+// and notes, embed descriptions hold 4096 characters.
+const (
+	maxLinkReportLine = 250
+	maxLinkReport     = 3000
+)
+
+// messageLinks returns the links in a message, in order and without
+// duplicates. Links wrapped in <> to hide their embed are found as well.
+func messageLinks(content string) []string {
+	var links []string
+	for _, field := range strings.Fields(content) {
+		link := urlPattern.FindString(strings.TrimLeft(field, "<(["))
+		link = strings.TrimRight(link, ">)]")
+		if link == "" || slices.Contains(links, link) {
+			continue
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// AddToQueue is a message command queueing every link of the message in
+// order, reporting which ones could not be loaded.
+func (b *Bot) AddToQueue(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	message := data.Resolved.Messages[data.TargetID]
+	links := messageLinks(message.Content)
+	if len(links) == 0 {
+		return b.SendResponse(event.Interaction, "No Links",
+			fmt.Sprintf("%s This message has no links to play.", IconEmpty), ColorDefault)
+	}
+
+	return b.playWith(event, playModeQueue, false, func() (*loadedTracks, error) {
+		return b.loadLinks(links, b.Settings(event.GuildID))
+	})
+}
+
+// loadLinks loads several links as one batch. Pending items of resolved
+// playlists are turned into placeholders to keep the links in order.
+func (b *Bot) loadLinks(links []string, settings Settings) (*loadedTracks, error) {
+	var (
+		batch  = &loadedTracks{From: "the message's links"}
+		report strings.Builder
+	)
+	if len(links) > maxMessageLinks {
+		batch.Notes = fmt.Sprintf("\n%s Only the first **%d** of **%d** links were loaded.", IconWarning, maxMessageLinks, len(links))
+		links = links[:maxMessageLinks]
+	}
+
+	for _, link := range links {
+		loaded, err := b.loadTracks(link, settings, playlistOptions{})
+		if err != nil {
+			report.WriteString(truncate(fmt.Sprintf("\n%s `%s`: %s", IconError, truncate(link, 80), truncate(err.Error(), 150)), maxLinkReportLine))
+			continue
+		}
+		for _, item := range loaded.Pending {
+			loaded.Tracks = append(loaded.Tracks, itemPlaceholder(item, settings.SearchType))
+		}
+
+		line := fmt.Sprintf("\n%s **%d** tracks from %s", IconSuccess, len(loaded.Tracks), loaded.From)
+		if loaded.From == "" {
+			line = fmt.Sprintf("\n%s %s", IconSuccess, trackLink(loaded.Tracks[0]))
+		}
+		report.WriteString(truncate(line, maxLinkReportLine))
+		batch.Tracks = append(batch.Tracks, loaded.Tracks...)
+		batch.ArtworkURL = cmp.Or(batch.ArtworkURL, loaded.ArtworkURL)
+	}
+
+	linkReport := strings.TrimRight(fitLines(report.String(), maxLinkReport), "\n")
+	if len(batch.Tracks) == 0 {
+		return nil, &loadError{Title: "No Results", Icon: IconEmpty, Color: ColorDefault,
+			Message: "None of the links could be loaded:" + linkReport}
+	}
+	batch.Notes = linkReport + batch.Notes
+	batch.limit(b.Config().MaxPlaylistTracks())
+	return batch, nil
+}
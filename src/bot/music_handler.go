@@ -2,16 +2,16 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/disgoorg/snowflake/v2"
 
-	"github.com/disgoorg/disgolink/v3/disgolink"
 	"github.com/disgoorg/disgolink/v3/lavalink"
 
 	bot_config "jukeboxitus/src/bot/config"
+	"jukeboxitus/src/bot/resolver"
 )
 
 func (b *Bot) Pause(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
@@ -73,45 +73,72 @@ func (b *Bot) Stop(event *discordgo.InteractionCreate, data discordgo.Applicatio
 	)
 }
 
-func (b *Bot) NowPlaying(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
-	player := b.Lavalink.ExistingPlayer(snowflake.MustParse(event.GuildID))
-	if player == nil {
-		return b.SendResponse(event.Interaction, "Player Status", fmt.Sprintf("%s No player found.", IconError), ColorError)
-	}
+// playMode is where /play, /playnext and /playnow put what they loaded.
+type playMode int
 
-	track := player.Track()
-	if track == nil {
-		return b.SendResponse(event.Interaction, "Player Status", fmt.Sprintf("%s Nothing playing.", IconEmpty), ColorDefault)
-	}
+const (
+	playModeQueue playMode = iota // the back of the queue
+	playModeNext                  // the front of the queue
+	playModeNow                   // interrupts the current track
+)
 
-	description := fmt.Sprintf("%s **Currently Playing**\n[`%s`](<%s>)\n\n`%s / %s`",
-		IconPlay, track.Info.Title, *track.Info.URI, formatPosition(player.Position()), formatPosition(track.Info.Length))
+func (b *Bot) Play(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	return b.play(event, data, playModeQueue)
+}
 
-	// Using the new complex function to show the artwork!
-	return b.SendComplexResponse(
-		event.Interaction,
-		"Now Playing",
-		description,
-		*track.Info.ArtworkURL,
-		ColorDefault,
-	)
+func (b *Bot) PlayNext(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	return b.play(event, data, playModeNext)
 }
 
-func (b *Bot) Play(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
-	identifier := data.Options[0].StringValue()
+func (b *Bot) PlayNow(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	return b.play(event, data, playModeNow)
+}
 
-	// 1. Handle Search Types
-	if !urlPattern.MatchString(identifier) && !searchPattern.MatchString(identifier) {
-		switch b.SearchType {
-		case bot_config.YouTube:
-			identifier = lavalink.SearchTypeYouTube.Apply(identifier)
-		case bot_config.YouTubeMusic:
-			identifier = lavalink.SearchTypeYouTubeMusic.Apply(identifier)
-		case bot_config.SoundCloud:
-			identifier = lavalink.SearchTypeSoundCloud.Apply(identifier)
+func (b *Bot) play(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData, mode playMode) error {
+	options := optionMap(data.Options)
+	settings := b.Settings(event.GuildID)
+
+	resume := true
+	if opt, ok := options["resume"]; ok {
+		resume = opt.BoolValue()
+	}
+
+	// Uploaded files are played through the node's HTTP source
+	if opt, ok := options["attachment"]; ok {
+		var attachment *discordgo.MessageAttachment
+		if data.Resolved != nil {
+			attachment = data.Resolved.Attachments[opt.StringValue()]
 		}
+		return b.playWith(event, mode, resume, func() (*loadedTracks, error) {
+			return b.loadAttachment(attachment, event.Member.User)
+		})
 	}
 
+	// 1. Handle Search Types
+	opt, ok := options["identifier"]
+	if !ok {
+		return b.SendResponse(event.Interaction, "Search Error",
+			fmt.Sprintf("%s Give a song link, a search query or an attachment.", IconError), ColorError)
+	}
+	sourceName := ""
+	if opt, ok := options["source"]; ok {
+		sourceName = opt.StringValue()
+	}
+	identifier, err := b.searchIdentifier(opt.StringValue(), sourceName, settings)
+	if err != nil {
+		return b.SendResponse(event.Interaction, "Search Error",
+			fmt.Sprintf("%s Unknown source `%s`.", IconError, sourceName), ColorError)
+	}
+
+	playlistOpts := playlistOptionsOf(options)
+	return b.playWith(event, mode, resume, func() (*loadedTracks, error) {
+		return b.loadTracks(identifier, settings, playlistOpts)
+	})
+}
+
+// playWith checks that the member is in a voice channel and defers the
+// response before calling load, then enqueues what it loaded.
+func (b *Bot) playWith(event *discordgo.InteractionCreate, mode playMode, resume bool, load func() (*loadedTracks, error)) error {
 	// 2. Voice State Check
 	voiceState, err := b.Session.State.VoiceState(event.GuildID, event.Member.User.ID)
 	if err != nil {
@@ -126,73 +153,341 @@ func (b *Bot) Play(event *discordgo.InteractionCreate, data discordgo.Applicatio
 		return err
 	}
 
-	player := b.Lavalink.Player(snowflake.MustParse(event.GuildID))
-	queue := b.Queues.Get(event.GuildID)
+	// 4. Load the tracks
+	loaded, err := load()
+	if err != nil {
+		return b.sendLoadError(event.Interaction, err)
+	}
+	return b.enqueue(event, voiceState.ChannelID, loaded, mode, b.Settings(event.GuildID), resume)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// loadedTracks is what an identifier given to /play, /playnext or /playnow
+// loaded to.
+type loadedTracks struct {
+	Tracks []lavalink.Track
+	// Pending are items of a resolved playlist that still have to be
+	// searched. They come after Tracks.
+	Pending []resolver.Item
+	// From describes where several tracks came from, e.g. "playlist: `Mix`".
+	// It is empty for a single track.
+	From       string
+	Search     bool
+	ArtworkURL string
+	Notes      string
+}
+
+// Len returns how many tracks were loaded, pending ones included.
+func (l *loadedTracks) Len() int {
+	return len(l.Tracks) + len(l.Pending)
+}
+
+// limit caps a playlist to the most tracks it may add.
+func (l *loadedTracks) limit(limit int) {
+	total := l.Len()
+	if total <= limit {
+		return
+	}
+	l.Notes += fmt.Sprintf("\n%s Only the first **%d** of **%d** tracks were loaded.", IconWarning, limit, total)
+	if len(l.Tracks) > limit {
+		l.Tracks = l.Tracks[:limit]
+	}
+	l.Pending = l.Pending[:limit-len(l.Tracks)]
+}
+
+// loadTracks loads identifier, keeping the part of a playlist picked by
+// playlistOpts.
+func (b *Bot) loadTracks(identifier string, settings Settings, playlistOpts playlistOptions) (*loadedTracks, error) {
+	// Links to services the node can't play are resolved to searches
+	if linkResolver := b.Resolvers.Find(identifier); linkResolver != nil {
+		return b.loadResolved(linkResolver, identifier, settings, playlistOpts)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.Config().LoadTracksTimeout())
 	defer cancel()
 
+	result, err := b.Lavalink.BestNode().LoadTracks(ctx, identifier)
+	if err != nil {
+		return nil, searchError(err)
+	}
+
+	switch data := result.Data.(type) {
+	case lavalink.Track:
+		return &loadedTracks{Tracks: []lavalink.Track{data}, ArtworkURL: trackArtwork(data)}, nil
+
+	case lavalink.Search:
+		if len(data) > 0 {
+			return &loadedTracks{Tracks: data[:1], Search: true, ArtworkURL: trackArtwork(data[0])}, nil
+		}
+
+	case lavalink.Playlist:
+		if len(data.Tracks) == 0 {
+			break
+		}
+		tracks, from, err := selectTracks(data.Tracks, data.Info.SelectedTrack, playlistOpts)
+		if err != nil {
+			return nil, playlistError(err)
+		}
+		loaded := &loadedTracks{
+			Tracks:     tracks,
+			From:       fmt.Sprintf("playlist: `%s`", data.Info.Name),
+			ArtworkURL: trackArtwork(tracks[0]),
+			Notes:      rangeNote(from, len(tracks), len(data.Tracks), playlistOpts),
+		}
+		loaded.limit(b.Config().MaxPlaylistTracks())
+		return loaded, nil
+
+	case lavalink.Exception:
+		return nil, searchError(data)
+	}
+
+	return nil, &loadError{Title: "No Results", Icon: IconEmpty, Color: ColorDefault,
+		Message: fmt.Sprintf("Nothing found for: `%s`", identifier)}
+}
+
+// loadError is a problem loading tracks, reported to the member as is.
+type loadError struct {
+	Title   string
+	Icon    string
+	Message string
+	Color   int
+}
+
+func (e *loadError) Error() string {
+	return e.Message
+}
+
+func searchError(err error) *loadError {
+	return &loadError{Title: "Search Error", Icon: IconError, Color: ColorError,
+		Message: fmt.Sprintf("Error: `%s`", err)}
+}
+
+func playlistError(err error) *loadError {
+	return &loadError{Title: "Playlist Error", Icon: IconError, Color: ColorError,
+		Message: fmt.Sprintf("Could not queue that part of the playlist: `%s`", err)}
+}
+
+func (b *Bot) sendLoadError(i *discordgo.Interaction, err error) error {
+	var loadErr *loadError
+	if !errors.As(err, &loadErr) {
+		loadErr = searchError(err)
+	}
+	return b.SendResponse(i, loadErr.Title, fmt.Sprintf("%s %s", loadErr.Icon, loadErr.Message), loadErr.Color)
+}
+
+// enqueue plays or queues what was loaded as mode asks and responds with what
+// was added. With playModeNow and resume, the interrupted track is queued
+// after the new ones and continues where it stopped.
+func (b *Bot) enqueue(event *discordgo.InteractionCreate, channelID string, loaded *loadedTracks, mode playMode, settings Settings, resume bool) error {
+	// The first track is played and shown below, whatever gets sliced off
+	if len(loaded.Tracks) == 0 {
+		return b.SendResponse(event.Interaction, "No Results",
+			fmt.Sprintf("%s Nothing playable was found.", IconEmpty), ColorDefault)
+	}
+	first := loaded.Tracks[0]
+
+	player, playing := b.currentTrack(event.GuildID)
+	idle := playing == nil
+	queue := b.Queues.Get(event.GuildID)
+	notes := loaded.Notes
+
+	requesterID := event.Member.User.ID
+	tracks := make([]lavalink.Track, 0, len(loaded.Tracks))
+	for _, track := range loaded.Tracks {
+		tracks = append(tracks, requestedBy(track, requesterID))
+	}
 	var toPlay *lavalink.Track
-	b.Lavalink.BestNode().LoadTracksHandler(ctx, identifier, disgolink.NewResultHandler(
-		// --- SINGLE TRACK LOADED ---
-		func(track lavalink.Track) {
-			b.SendComplexResponse(event.Interaction, "Track Added",
-				fmt.Sprintf("%s Added [`%s`](<%s>) to queue.", IconPlay, track.Info.Title, *track.Info.URI),
-				*track.Info.ArtworkURL, ColorSuccess)
-
-			if player.Track() == nil {
-				toPlay = &track
-			} else {
-				queue.Add(track)
-			}
-		},
-		// --- PLAYLIST LOADED ---
-		func(playlist lavalink.Playlist) {
-			b.SendComplexResponse(event.Interaction, "Playlist Added",
-				fmt.Sprintf("%s Loaded **%d** tracks from playlist: `%s`", IconQueue, len(playlist.Tracks), playlist.Info.Name),
-				*playlist.Tracks[0].Info.ArtworkURL, ColorSuccess)
-
-			if player.Track() == nil {
-				toPlay = &playlist.Tracks[0]
-				queue.Add(playlist.Tracks[1:]...)
-			} else {
-				queue.Add(playlist.Tracks...)
-			}
-		},
-		// --- SEARCH RESULT LOADED ---
-		func(tracks []lavalink.Track) {
-			b.SendComplexResponse(event.Interaction, "Search Result",
-				fmt.Sprintf("%s Playing search result: [`%s`](<%s>)", IconSearch, tracks[0].Info.Title, *tracks[0].Info.URI),
-				*tracks[0].Info.ArtworkURL, ColorSuccess)
-
-			if player.Track() == nil {
-				toPlay = &tracks[0]
-			} else {
-				queue.Add(tracks[0])
+	if idle || mode == playModeNow {
+		toPlay = &tracks[0]
+		tracks = tracks[1:]
+	}
+
+	// Small resolved playlists added to the back of the queue are searched in
+	// the background, anything else is queued as placeholders right away
+	threshold := b.Config().LazyThreshold()
+	lazy := threshold >= 0 && len(tracks)+len(loaded.Pending) > threshold
+	var pending []resolver.Item
+	if mode == playModeQueue && !lazy {
+		pending = loaded.Pending
+	} else {
+		for _, item := range loaded.Pending {
+			tracks = append(tracks, requestedBy(itemPlaceholder(item, settings.SearchType), requesterID))
+		}
+	}
+	if lazy {
+		// Huge playlists only keep what the queue displays, each track is
+		// loaded again shortly before it plays
+		for i, track := range tracks {
+			tracks[i] = placeholderOf(track)
+		}
+	}
+
+	if limit := settings.MaxQueueLength; limit > 0 {
+		free := max(limit-queue.Len(), 0)
+		if toPlay == nil && free == 0 {
+			return b.sendQueueFull(event.Interaction, limit)
+		}
+		if len(tracks)+len(pending) > free {
+			notes += fmt.Sprintf("\n%s **%d** tracks were skipped, the queue is limited to **%d** tracks.",
+				IconWarning, len(tracks)+len(pending)-free, limit)
+			if len(tracks) > free {
+				tracks = tracks[:free]
 			}
-		},
-		// --- NOTHING FOUND ---
-		func() {
-			b.SendResponse(event.Interaction, "No Results",
-				fmt.Sprintf("%s Nothing found for: `%s`", IconEmpty, identifier), ColorDefault)
-		},
-		// --- ERROR ---
-		func(err error) {
-			b.SendResponse(event.Interaction, "Search Error",
+			pending = pending[:free-len(tracks)]
+		}
+	}
+	added := len(tracks) + len(pending)
+	if toPlay != nil {
+		added++
+	}
+
+	// Placeholders count towards the progress shown in the response as they
+	// are loaded
+	placeholders := 0
+	for i, track := range tracks {
+		if isPlaceholder(track) {
+			data := trackData(track)
+			data.Batch = event.ID
+			tracks[i] = withTrackData(track, data)
+			placeholders++
+		}
+	}
+	if placeholders > 0 {
+		b.lazyBatches.add(event.ID, lazyBatch{guildID: event.GuildID, total: placeholders})
+	}
+
+	var interrupted *lavalink.Track
+	if mode == playModeNow && !idle && resume {
+		current := resumeAt(*playing, player.Position())
+		interrupted = &current
+		tracks = append(tracks, current)
+	}
+
+	if mode == playModeQueue {
+		queue.Add(tracks...)
+	} else {
+		queue.AddFront(tracks...)
+	}
+	if toPlay != nil {
+		joinChannelID := channelID
+		if idle {
+			b.rememberTextChannel(event.GuildID, event.ChannelID)
+		} else {
+			joinChannelID = ""
+		}
+		if err := b.startPlaying(event.GuildID, joinChannelID, *toPlay); err != nil {
+			return b.SendResponse(event.Interaction, "Playback Error",
 				fmt.Sprintf("%s Error: `%s`", IconError, err), ColorError)
-		},
-	))
+		}
+	}
+	go b.prefetchNext(event.GuildID)
 
-	if toPlay == nil {
-		return nil
+	// Build the response
+	var title, description string
+	if loaded.From == "" {
+		switch {
+		case mode == playModeNow:
+			title = "Now Playing"
+			description = fmt.Sprintf("%s Playing %s now.", IconPlay, trackLink(first))
+		case mode == playModeNext:
+			title = "Playing Next"
+			description = fmt.Sprintf("%s %s will play next.", IconSkip, trackLink(first))
+		case loaded.Search:
+			title = "Search Result"
+			description = fmt.Sprintf("%s Playing search result: %s", IconSearch, trackLink(first))
+		default:
+			title = "Track Added"
+			description = fmt.Sprintf("%s Added %s to queue.", IconPlay, trackLink(first))
+		}
+	} else {
+		title = "Playlist Added"
+		verb := "Loaded"
+		if len(pending) > 0 {
+			verb = "Adding"
+		}
+		description = fmt.Sprintf("%s %s **%d** tracks from %s", IconQueue, verb, added, loaded.From)
+		switch mode {
+		case playModeNow:
+			description += fmt.Sprintf("\n%s Playing %s now.", IconPlay, trackLink(first))
+		case playModeNext:
+			description += fmt.Sprintf("\n%s They play next.", IconSkip)
+		}
+		if lazy {
+			description += "\nTracks are loaded shortly before they play."
+		}
 	}
+	if interrupted != nil {
+		description += fmt.Sprintf("\n%s %s resumes afterwards.", IconRepeat, trackLink(*interrupted))
+	}
+	description += notes
+
+	if len(pending) > 0 {
+		return b.queuePending(event, description, loaded.ArtworkURL, pending, settings)
+	}
+	if placeholders > 0 {
+		return b.reportPlaceholders(event, title, description, loaded.ArtworkURL, placeholders)
+	}
+	return b.SendComplexResponse(event.Interaction, title, description, loaded.ArtworkURL, ColorSuccess)
+}
+
+// queueOrPlay plays track in the voice channel when nothing is playing yet,
+// otherwise it adds it to the queue. It reports whether playback started, in
+// which case tracks are announced in textChannelID.
+func (b *Bot) queueOrPlay(guildID string, channelID string, textChannelID string, track lavalink.Track) (bool, error) {
+	if _, current := b.currentTrack(guildID); current != nil {
+		b.Queues.Get(guildID).Add(track)
+		return false, nil
+	}
+	b.rememberTextChannel(guildID, textChannelID)
+	return true, b.startPlaying(guildID, channelID, track)
+}
+
+// startPlaying plays track right away, replacing whatever is playing. When
+// channelID is set, the bot joins that voice channel first.
+func (b *Bot) startPlaying(guildID string, channelID string, track lavalink.Track) error {
+	newPlayer := b.Lavalink.ExistingPlayer(snowflake.MustParse(guildID)) == nil
+	player := b.Lavalink.Player(snowflake.MustParse(guildID))
 
-	// Join and Play
-	if err := b.Session.ChannelVoiceJoinManual(event.GuildID, voiceState.ChannelID, false, false); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), b.Config().LoadTracksTimeout())
+	track, err := b.loadPlaceholder(ctx, track)
+	cancel()
+	if err != nil {
 		return err
 	}
 
-	return player.Update(context.Background(), lavalink.WithTrack(*toPlay))
+	if channelID != "" {
+		if err := b.Session.ChannelVoiceJoinManual(guildID, channelID, false, false); err != nil {
+			return err
+		}
+	}
+
+	opts := playOpts(track)
+	if volume := b.Settings(guildID).DefaultVolume; newPlayer && volume > 0 {
+		opts = append(opts, lavalink.WithVolume(volume))
+	}
+	return player.Update(context.Background(), opts...)
+}
+
+// optionMap indexes command options by name.
+func optionMap(options []*discordgo.ApplicationCommandInteractionDataOption) map[string]*discordgo.ApplicationCommandInteractionDataOption {
+	m := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, opt := range options {
+		m[opt.Name] = opt
+	}
+	return m
+}
+
+// applySearchType turns a plain query into a search on the given source.
+func applySearchType(searchType bot_config.SearchType, query string) string {
+	return searchTypePrefix(searchType).Apply(query)
+}
+
+func searchTypePrefix(searchType bot_config.SearchType) lavalink.SearchType {
+	return lavalink.SearchType(searchType.Prefix())
+}
+
+func (b *Bot) sendQueueFull(i *discordgo.Interaction, limit int) error {
+	return b.SendResponse(i, "Queue Full",
+		fmt.Sprintf("%s The queue is limited to **%d** tracks on this server.", IconWarning, limit), ColorWarning)
 }
 
 func formatPosition(position lavalink.Duration) string {
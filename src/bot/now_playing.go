@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/disgolink"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/log"
+)
+
+// seekBarWidth is how many segments the /now-playing progress bar has.
+const seekBarWidth = 15
+
+// liveCards follows the messages that are kept up to date while a guild
+// plays, at most one per guild, e.g. persistent /now-playing cards.
+type liveCards struct {
+	mu    sync.Mutex
+	cards map[string]*liveCard
+}
+
+type liveCard struct {
+	cancel context.CancelFunc
+}
+
+// start replaces the card of a guild. The returned context ends when the card
+// is replaced or stopped, and done must be called once it stops updating.
+func (c *liveCards) start(guildID string) (ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	card := &liveCard{cancel: cancel}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cards == nil {
+		c.cards = make(map[string]*liveCard)
+	}
+	if previous, ok := c.cards[guildID]; ok {
+		previous.cancel()
+	}
+	c.cards[guildID] = card
+
+	return ctx, func() {
+		c.mu.Lock()
+		if c.cards[guildID] == card {
+			delete(c.cards, guildID)
+		}
+		c.mu.Unlock()
+		cancel()
+	}
+}
+
+func (c *liveCards) stop(guildID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if card, ok := c.cards[guildID]; ok {
+		card.cancel()
+		delete(c.cards, guildID)
+	}
+}
+
+func (b *Bot) NowPlaying(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	player, track := b.currentTrack(event.GuildID)
+	if player == nil {
+		return b.SendResponse(event.Interaction, "Player Status", fmt.Sprintf("%s No player found.", IconError), ColorError)
+	}
+	if track == nil {
+		return b.SendResponse(event.Interaction, "Player Status", fmt.Sprintf("%s Nothing playing.", IconEmpty), ColorDefault)
+	}
+
+	persistent := false
+	if opt, ok := optionMap(data.Options)["persistent"]; ok {
+		persistent = opt.BoolValue()
+	}
+
+	embed := b.nowPlayingEmbed(event.GuildID, player, track)
+	if persistent {
+		embed.Footer.Text = b.persistentFooter()
+	}
+	if err := b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	}); err != nil || !persistent {
+		return err
+	}
+
+	message, err := b.Session.InteractionResponse(event.Interaction)
+	if err != nil {
+		return err
+	}
+	b.followNowPlaying(event.GuildID, message.ChannelID, message.ID)
+	return nil
+}
+
+// nowPlayingEmbed renders the track a player plays with a progress bar and
+// the player's state.
+func (b *Bot) nowPlayingEmbed(guildID string, player disgolink.Player, playing *lavalink.Track) *discordgo.MessageEmbed {
+	track := *playing
+	info := trackInfo(track)
+	position := player.Position()
+
+	icon := IconPlay
+	if player.Paused() {
+		icon = IconPause
+	}
+	description := fmt.Sprintf("%s %s\n\n", icon, trackLink(track))
+	if track.Info.IsStream {
+		description += formatLength(track)
+	} else {
+		description += fmt.Sprintf("%s\n`%s / %s`", seekBar(position, track.Info.Length, seekBarWidth),
+			formatPosition(position), formatLength(track))
+	}
+	if title := b.streamTitle(guildID); title != "" {
+		description += fmt.Sprintf("\n%s **On air:** %s", IconRadio, title)
+	}
+
+	requester := "-"
+	if id := trackData(track).RequesterID; id != "" {
+		requester = fmt.Sprintf("<@%s>", id)
+	}
+	filters := "None"
+	if names := activeFilters(player.Filters()); len(names) > 0 {
+		filters = strings.Join(names, ", ")
+	}
+	field := func(name string, value string) *discordgo.MessageEmbedField {
+		return &discordgo.MessageEmbedField{Name: name, Value: cmp.Or(value, "-"), Inline: true}
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Now Playing",
+		Description: description,
+		Color:       ColorDefault,
+		Fields: []*discordgo.MessageEmbedField{
+			field("Author", info.Author),
+			field("Requested by", requester),
+			field("Source", info.SourceName),
+			field("Volume", fmt.Sprintf("%d%%", player.Volume())),
+			field("Loop", b.Queues.Get(guildID).Type.String()),
+			field("Filters", filters),
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Jukeboxitus Music",
+		},
+	}
+	if artwork := trackArtwork(track); artwork != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: artwork}
+	}
+	return embed
+}
+
+// followNowPlaying edits a /now-playing card every few seconds, replacing
+// the previous persistent card of the guild. It stops once the player is
+// paused, stops playing or is gone, or when the card can't be edited anymore.
+func (b *Bot) followNowPlaying(guildID string, channelID string, messageID string) {
+	ctx, done := b.nowPlaying.start(guildID)
+	go func() {
+		defer done()
+
+		// Edits share a rate limit bucket per channel, which discordgo waits
+		// for, and the interval is never shorter than a few seconds
+		ticker := time.NewTicker(b.Config().NowPlayingUpdateInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			player, track := b.currentTrack(guildID)
+			if track == nil {
+				return
+			}
+			embed := b.nowPlayingEmbed(guildID, player, track)
+			embed.Footer.Text = b.persistentFooter()
+			if _, err := b.Session.ChannelMessageEditEmbed(channelID, messageID, embed); err != nil {
+				log.Debug("failed to update now playing card: ", err)
+				return
+			}
+			// The card is left showing the paused state
+			if player.Paused() {
+				return
+			}
+		}
+	}()
+}
+
+func (b *Bot) persistentFooter() string {
+	return fmt.Sprintf("Jukeboxitus Music • Updates every %s", b.Config().NowPlayingUpdateInterval())
+}
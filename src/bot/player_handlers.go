@@ -3,7 +3,9 @@ package bot
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/disgoorg/log"
 
 	"github.com/disgoorg/disgolink/v3/disgolink"
@@ -12,53 +14,98 @@ import (
 
 func (b *Bot) OnPlayerPause(player disgolink.Player, event lavalink.PlayerPauseEvent) {
 	fmt.Printf("onPlayerPause: %v\n", event)
+	b.History.Pause(event.GuildID().String(), time.Now())
+	b.startIdleTimer(event.GuildID().String())
 }
 
 func (b *Bot) OnPlayerResume(player disgolink.Player, event lavalink.PlayerResumeEvent) {
 	fmt.Printf("onPlayerResume: %v\n", event)
+	b.History.Resume(event.GuildID().String(), time.Now())
+	b.stopIdleTimer(event.GuildID().String())
 }
 
 func (b *Bot) OnTrackStart(player disgolink.Player, event lavalink.TrackStartEvent) {
 	fmt.Printf("onTrackStart: %v\n", event)
+
+	guildID := event.GuildID().String()
+	b.stopIdleTimer(guildID)
+	b.History.Start(guildID, event.Track, time.Now())
+	b.watchStreamTitle(guildID, event.Track)
+	go b.prefetchNext(guildID)
+
+	b.announceNowPlaying(guildID, event.Track)
+}
+
+// announceNowPlaying posts a compact card for the track that just started.
+func (b *Bot) announceNowPlaying(guildID string, track lavalink.Track) {
+	description := fmt.Sprintf("%s %s", IconPlay, trackLink(track))
+	if author := trackInfo(track).Author; author != "" {
+		description += fmt.Sprintf(" by **%s**", author)
+	}
+	description += fmt.Sprintf(" `%s`%s", formatLength(track), requesterMention(track))
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Now Playing",
+		Description: description,
+		Color:       ColorDefault,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Jukeboxitus Music",
+		},
+	}
+	if artworkURL := trackArtwork(track); artworkURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: artworkURL}
+	}
+	b.announce(guildID, embed)
 }
 
 func (b *Bot) OnTrackEnd(player disgolink.Player, event lavalink.TrackEndEvent) {
 	fmt.Printf("onTrackEnd: %v\n", event)
 
+	b.stopStreamTitle(event.GuildID().String())
+	if err := b.History.End(event.GuildID().String(), event.Track, event.Reason, time.Now()); err != nil {
+		log.Error("failed to record the play: ", err)
+	}
+
 	// MayStartNext is false if the track was stopped or replaced manually
 	if !event.Reason.MayStartNext() {
 		return
 	}
 
 	queue := b.Queues.Get(event.GuildID().String())
-	var (
-		nextTrack lavalink.Track
-		ok        bool
-	)
 
 	switch queue.Type {
-	case QueueTypeNormal:
-		nextTrack, ok = queue.Next()
-
 	case QueueTypeRepeatTrack:
-		nextTrack = event.Track
-		ok = true // Fix: Ensure we proceed to play
+		if err := player.Update(context.Background(), lavalink.WithTrack(event.Track)); err != nil {
+			log.Error("Failed to play next track: ", err)
+		}
+		return
 
 	case QueueTypeRepeatQueue:
 		queue.Add(event.Track)
-		nextTrack, ok = queue.Next()
-		// ok will be true because we just added a track
 	}
 
-	// If no tracks are left in the queue and we aren't repeating
-	if !ok {
-		return
-	}
+	// Listeners run in the node's read loop, so loading placeholders here
+	// would hold up the events of every guild on the node
+	go b.advance(player, queue, event.Track)
+}
 
-	// Play the determined next track
-	if err := player.Update(context.Background(), lavalink.WithTrack(nextTrack)); err != nil {
+// advance plays the next track of the queue after ended, loading it first if
+// it is a placeholder. Once the queue ran out, autoplay continues or the idle
+// timer starts.
+func (b *Bot) advance(player disgolink.Player, queue *Queue, ended lavalink.Track) {
+	_, ok, err := b.playNext(player, queue)
+	if err != nil {
 		log.Error("Failed to play next track: ", err)
 	}
+
+	// If no tracks are left in the queue and we aren't repeating
+	if !ok {
+		if b.Settings(player.GuildID().String()).Autoplay {
+			b.autoplay(player, ended)
+			return
+		}
+		b.startIdleTimer(player.GuildID().String())
+	}
 }
 
 func (b *Bot) OnTrackException(player disgolink.Player, event lavalink.TrackExceptionEvent) {
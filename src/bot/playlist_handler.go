@@ -0,0 +1,390 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+)
+
+const (
+	maxPlaylistsPerOwner = 25
+	playlistShowLimit    = 20
+)
+
+var errPlaylistForbidden = errors.New("only its creator, DJs and server managers can change this playlist")
+
+func (b *Bot) PlaylistCommand(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	subcommand := data.Options[0]
+	options := optionMap(subcommand.Options)
+	owner := playlistOwner(event, options)
+
+	name := ""
+	if opt, ok := options["name"]; ok {
+		name = strings.TrimSpace(opt.StringValue())
+	}
+
+	switch subcommand.Name {
+	case "create":
+		return b.createPlaylist(event, owner, name)
+	case "add":
+		return b.addToPlaylist(event, owner, name, options)
+	case "remove":
+		return b.removeFromPlaylist(event, owner, name, int(options["position"].IntValue()))
+	case "show":
+		return b.showPlaylist(event, owner, name)
+	case "play":
+		return b.playPlaylist(event, owner, name, options)
+	case "delete":
+		return b.deletePlaylist(event, owner, name)
+	case "save-queue":
+		return b.saveQueue(event, owner, name)
+	default:
+		return b.listPlaylists(event, owner)
+	}
+}
+
+// playlistOwner returns whose playlists a command is about, the member's own
+// unless the server's were asked for.
+func playlistOwner(event *discordgo.InteractionCreate, options map[string]*discordgo.ApplicationCommandInteractionDataOption) string {
+	if opt, ok := options["scope"]; ok && opt.StringValue() == "server" {
+		return guildOwner(event.GuildID)
+	}
+	return userOwner(event.Member.User.ID)
+}
+
+// canEditPlaylist reports whether the member may change a playlist. Server
+// playlists can be changed by their creator, DJs and server managers.
+func (b *Bot) canEditPlaylist(event *discordgo.InteractionCreate, owner string, playlist SavedPlaylist) bool {
+	if owner == userOwner(event.Member.User.ID) || playlist.CreatorID == event.Member.User.ID {
+		return true
+	}
+	if event.Member.Permissions&discordgo.PermissionManageServer != 0 {
+		return true
+	}
+	djRole := b.Settings(event.GuildID).DJRole
+	return djRole != "" && slices.Contains(event.Member.Roles, djRole)
+}
+
+func (b *Bot) sendPlaylistError(event *discordgo.InteractionCreate, name string, err error) error {
+	switch {
+	case errors.Is(err, errPlaylistNotFound):
+		return b.SendResponse(event.Interaction, "Playlist Error",
+			fmt.Sprintf("%s There is no playlist named `%s`.", IconError, name), ColorError)
+	case errors.Is(err, errPlaylistExists), errors.Is(err, errPlaylistForbidden):
+		return b.SendResponse(event.Interaction, "Playlist Error",
+			fmt.Sprintf("%s Cannot change `%s`: %s.", IconError, name, err), ColorError)
+	default:
+		return b.SendResponse(event.Interaction, "Playlist Error",
+			fmt.Sprintf("%s Could not update `%s`: `%s`", IconError, name, err), ColorError)
+	}
+}
+
+func (b *Bot) createPlaylist(event *discordgo.InteractionCreate, owner string, name string) error {
+	if name == "" {
+		return b.SendResponse(event.Interaction, "Playlist Error",
+			fmt.Sprintf("%s Give the playlist a name.", IconError), ColorError)
+	}
+	if len(b.Playlists.List(owner)) >= maxPlaylistsPerOwner {
+		return b.SendResponse(event.Interaction, "Playlist Error",
+			fmt.Sprintf("%s There can be at most **%d** playlists.", IconError, maxPlaylistsPerOwner), ColorError)
+	}
+	if err := b.Playlists.Create(owner, SavedPlaylist{Name: name, CreatorID: event.Member.User.ID}); err != nil {
+		return b.sendPlaylistError(event, name, err)
+	}
+	return b.SendResponse(event.Interaction, "Playlist Created",
+		fmt.Sprintf("%s Created playlist `%s`. Add tracks with `/playlist add`.", IconSuccess, name), ColorSuccess)
+}
+
+// addToPlaylist adds what identifier loads to a playlist, or the current
+// track when no identifier is given.
+func (b *Bot) addToPlaylist(event *discordgo.InteractionCreate, owner string, name string, options map[string]*discordgo.ApplicationCommandInteractionDataOption) error {
+	playlist, ok := b.Playlists.Get(owner, name)
+	if !ok {
+		return b.sendPlaylistError(event, name, errPlaylistNotFound)
+	}
+	if !b.canEditPlaylist(event, owner, playlist) {
+		return b.sendPlaylistError(event, name, errPlaylistForbidden)
+	}
+
+	var tracks []lavalink.Track
+	opt, ok := options["identifier"]
+	if !ok {
+		_, current := b.currentTrack(event.GuildID)
+		if current == nil {
+			return b.SendResponse(event.Interaction, "Playlist Error",
+				fmt.Sprintf("%s Nothing is playing, give a song link or search query to add.", IconError), ColorError)
+		}
+		tracks = []lavalink.Track{*current}
+	} else {
+		settings := b.Settings(event.GuildID)
+		identifier, err := b.searchIdentifier(opt.StringValue(), "", settings)
+		if err != nil {
+			return b.sendLoadError(event.Interaction, err)
+		}
+
+		if err := b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		}); err != nil {
+			return err
+		}
+		loaded, err := b.loadTracks(identifier, settings, playlistOptions{})
+		if err != nil {
+			return b.sendLoadError(event.Interaction, err)
+		}
+		tracks = loaded.Tracks
+		for _, item := range loaded.Pending {
+			tracks = append(tracks, itemPlaceholder(item, settings.SearchType))
+		}
+	}
+
+	// Playlists only keep tracks that can be played again, resolved links
+	// are searched now
+	limit := b.Config().MaxPlaylistTracks()
+	limited := len(tracks) > limit
+	tracks, failed := b.loadPlaceholders(tracks[:min(len(tracks), limit)])
+	if len(tracks) == 0 {
+		return b.SendResponse(event.Interaction, "Playlist Error",
+			fmt.Sprintf("%s None of the tracks could be found.", IconError), ColorError)
+	}
+	for i, track := range tracks {
+		tracks[i] = savedTrack(track)
+	}
+	added := 0
+	playlist, err := b.Playlists.Update(owner, name, func(playlist *SavedPlaylist) error {
+		added = min(len(tracks), max(limit-len(playlist.Tracks), 0))
+		playlist.Tracks = append(playlist.Tracks, tracks[:added]...)
+		return nil
+	})
+	if err != nil {
+		return b.sendPlaylistError(event, name, err)
+	}
+
+	description := fmt.Sprintf("%s Added %s to `%s`, it now has **%d** tracks.", IconSuccess, trackLink(tracks[0]), playlist.Name, len(playlist.Tracks))
+	if len(tracks) > 1 {
+		description = fmt.Sprintf("%s Added **%d** tracks to `%s`, it now has **%d** tracks.", IconSuccess, added, playlist.Name, len(playlist.Tracks))
+	}
+	if limited || added < len(tracks) {
+		description += fmt.Sprintf("\n%s Playlists are limited to **%d** tracks.", IconWarning, limit)
+	}
+	description += notFoundNote(failed)
+	return b.SendResponse(event.Interaction, "Playlist Updated", description, ColorSuccess)
+}
+
+func (b *Bot) removeFromPlaylist(event *discordgo.InteractionCreate, owner string, name string, position int) error {
+	var removed lavalink.Track
+	_, err := b.Playlists.Update(owner, name, func(playlist *SavedPlaylist) error {
+		if !b.canEditPlaylist(event, owner, *playlist) {
+			return errPlaylistForbidden
+		}
+		if position < 1 || position > len(playlist.Tracks) {
+			return fmt.Errorf("the playlist has %d tracks", len(playlist.Tracks))
+		}
+		removed = playlist.Tracks[position-1]
+		playlist.Tracks = slices.Delete(playlist.Tracks, position-1, position)
+		return nil
+	})
+	if err != nil {
+		return b.sendPlaylistError(event, name, err)
+	}
+	return b.SendResponse(event.Interaction, "Playlist Updated",
+		fmt.Sprintf("%s Removed %s from `%s`.", IconSuccess, trackLink(removed), name), ColorSuccess)
+}
+
+func (b *Bot) listPlaylists(event *discordgo.InteractionCreate, owner string) error {
+	playlists := b.Playlists.List(owner)
+	if len(playlists) == 0 {
+		return b.SendResponse(event.Interaction, "Playlists",
+			fmt.Sprintf("%s No playlists yet, create one with `/playlist create`.", IconEmpty), ColorDefault)
+	}
+
+	var sb strings.Builder
+	for _, playlist := range playlists {
+		fmt.Fprintf(&sb, "%s **%s** · %d tracks\n", IconQueue, playlist.Name, len(playlist.Tracks))
+	}
+	return b.SendResponse(event.Interaction, "Playlists", sb.String(), ColorDefault)
+}
+
+func (b *Bot) showPlaylist(event *discordgo.InteractionCreate, owner string, name string) error {
+	playlist, ok := b.Playlists.Get(owner, name)
+	if !ok {
+		return b.sendPlaylistError(event, name, errPlaylistNotFound)
+	}
+	if len(playlist.Tracks) == 0 {
+		return b.SendResponse(event.Interaction, playlist.Name,
+			fmt.Sprintf("%s This playlist is empty.", IconEmpty), ColorDefault)
+	}
+
+	var (
+		sb    strings.Builder
+		total lavalink.Duration
+	)
+	for i, track := range playlist.Tracks {
+		total += track.Info.Length
+		if i < playlistShowLimit {
+			fmt.Fprintf(&sb, "**%d.** %s `%s`\n", i+1, trackLink(track), formatLength(track))
+		}
+	}
+	if len(playlist.Tracks) > playlistShowLimit {
+		fmt.Fprintf(&sb, "...and **%d** more\n", len(playlist.Tracks)-playlistShowLimit)
+	}
+	fmt.Fprintf(&sb, "\n**%d** tracks · `%s`", len(playlist.Tracks), formatPosition(total))
+
+	return b.SendComplexResponse(event.Interaction, fmt.Sprintf("%s %s", IconQueue, playlist.Name),
+		sb.String(), trackArtwork(playlist.Tracks[0]), ColorDefault)
+}
+
+func (b *Bot) playPlaylist(event *discordgo.InteractionCreate, owner string, name string, options map[string]*discordgo.ApplicationCommandInteractionDataOption) error {
+	playlist, ok := b.Playlists.Get(owner, name)
+	if !ok {
+		return b.sendPlaylistError(event, name, errPlaylistNotFound)
+	}
+	if len(playlist.Tracks) == 0 {
+		return b.SendResponse(event.Interaction, "Playlist Error",
+			fmt.Sprintf("%s `%s` is empty.", IconEmpty, playlist.Name), ColorDefault)
+	}
+
+	playlistOpts := playlistOptionsOf(options)
+	return b.playWith(event, playModeQueue, false, func() (*loadedTracks, error) {
+		tracks, from, err := selectTracks(playlist.Tracks, -1, playlistOpts)
+		if err != nil {
+			return nil, playlistError(err)
+		}
+		loaded := &loadedTracks{
+			Tracks:     tracks,
+			From:       fmt.Sprintf("saved playlist: `%s`", playlist.Name),
+			ArtworkURL: trackArtwork(tracks[0]),
+			Notes:      rangeNote(from, len(tracks), len(playlist.Tracks), playlistOpts),
+		}
+		loaded.limit(b.Config().MaxPlaylistTracks())
+		return loaded, nil
+	})
+}
+
+func (b *Bot) deletePlaylist(event *discordgo.InteractionCreate, owner string, name string) error {
+	playlist, ok := b.Playlists.Get(owner, name)
+	if !ok {
+		return b.sendPlaylistError(event, name, errPlaylistNotFound)
+	}
+	if !b.canEditPlaylist(event, owner, playlist) {
+		return b.sendPlaylistError(event, name, errPlaylistForbidden)
+	}
+	if err := b.Playlists.Delete(owner, name); err != nil {
+		return b.sendPlaylistError(event, name, err)
+	}
+	return b.SendResponse(event.Interaction, "Playlist Deleted",
+		fmt.Sprintf("%s Deleted playlist `%s`.", IconSuccess, playlist.Name), ColorSuccess)
+}
+
+// saveQueue saves the current track and the queue as a playlist, replacing
+// the tracks of an existing one.
+func (b *Bot) saveQueue(event *discordgo.InteractionCreate, owner string, name string) error {
+	var tracks []lavalink.Track
+	if _, current := b.currentTrack(event.GuildID); current != nil {
+		tracks = append(tracks, *current)
+	}
+	tracks = append(tracks, b.Queues.Get(event.GuildID).List()...)
+	if len(tracks) == 0 {
+		return b.SendResponse(event.Interaction, "Playlist Error",
+			fmt.Sprintf("%s The queue is empty, there is nothing to save.", IconEmpty), ColorDefault)
+	}
+
+	var notes string
+	if limit := b.Config().MaxPlaylistTracks(); len(tracks) > limit {
+		notes = fmt.Sprintf("\n%s Playlists are limited to **%d** tracks.", IconWarning, limit)
+		tracks = tracks[:limit]
+	}
+
+	// Tracks of huge or resolved playlists wait in the queue as placeholders
+	// and are loaded before they are saved, which may take a while
+	if slices.ContainsFunc(tracks, isPlaceholder) {
+		if err := b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		}); err != nil {
+			return err
+		}
+		var failed int
+		tracks, failed = b.loadPlaceholders(tracks)
+		notes += notFoundNote(failed)
+		if len(tracks) == 0 {
+			return b.SendResponse(event.Interaction, "Playlist Error",
+				fmt.Sprintf("%s None of the queued tracks could be loaded.%s", IconError, notes), ColorError)
+		}
+	}
+	for i, track := range tracks {
+		tracks[i] = savedTrack(track)
+	}
+
+	_, err := b.Playlists.Update(owner, name, func(playlist *SavedPlaylist) error {
+		if !b.canEditPlaylist(event, owner, *playlist) {
+			return errPlaylistForbidden
+		}
+		playlist.Tracks = tracks
+		return nil
+	})
+	if errors.Is(err, errPlaylistNotFound) {
+		if len(b.Playlists.List(owner)) >= maxPlaylistsPerOwner {
+			return b.SendResponse(event.Interaction, "Playlist Error",
+				fmt.Sprintf("%s There can be at most **%d** playlists.", IconError, maxPlaylistsPerOwner), ColorError)
+		}
+		err = b.Playlists.Create(owner, SavedPlaylist{Name: name, CreatorID: event.Member.User.ID, Tracks: tracks})
+	}
+	if err != nil {
+		return b.sendPlaylistError(event, name, err)
+	}
+
+	return b.SendResponse(event.Interaction, "Queue Saved",
+		fmt.Sprintf("%s Saved **%d** tracks as `%s`.%s", IconSuccess, len(tracks), name, notes), ColorSuccess)
+}
+
+// notFoundNote tells how many tracks could not be loaded, if any.
+func notFoundNote(failed int) string {
+	if failed == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n%s **%d** tracks could not be loaded and were left out.", IconWarning, failed)
+}
+
+// savedTrack strips the playback state and the requester from a track before
+// it is saved. Whoever plays the playlist later requests it again.
+func savedTrack(track lavalink.Track) lavalink.Track {
+	if data := trackData(track); data.StartPosition != 0 || data.Batch != "" || data.RequesterID != "" {
+		data.StartPosition = 0
+		data.Batch = ""
+		data.RequesterID = ""
+		return withTrackData(track, data)
+	}
+	return track
+}
+
+func (b *Bot) PlaylistAutocomplete(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	options := optionMap(data.Options[0].Options)
+	typed := ""
+	if opt, ok := options["name"]; ok {
+		typed = strings.ToLower(opt.StringValue())
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, playlist := range b.Playlists.List(playlistOwner(event, options)) {
+		if !strings.Contains(strings.ToLower(playlist.Name), typed) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  playlist.Name,
+			Value: playlist.Name,
+		})
+	}
+	if len(choices) > 25 {
+		choices = choices[:25]
+	}
+
+	return b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
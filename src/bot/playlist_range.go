@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// playlistOptions pick the part of a playlist that gets queued, see
+// playlistStartOption and friends.
+type playlistOptions struct {
+	// Start and End count from 1 and are inclusive, 0 when unset.
+	Start   int
+	End     int
+	Shuffle bool
+}
+
+func playlistOptionsOf(options map[string]*discordgo.ApplicationCommandInteractionDataOption) playlistOptions {
+	var opts playlistOptions
+	if opt, ok := options["start"]; ok {
+		opts.Start = int(opt.IntValue())
+	}
+	if opt, ok := options["end"]; ok {
+		opts.End = int(opt.IntValue())
+	}
+	if opt, ok := options["shuffle"]; ok {
+		opts.Shuffle = opt.BoolValue()
+	}
+	return opts
+}
+
+// selectTracks returns the part of a playlist to queue and the index it
+// starts at. selected is the index of the track the link pointed at, or -1.
+// Without a start option the playlist starts there, and that track stays
+// first when shuffling.
+func selectTracks[T any](items []T, selected int, opts playlistOptions) ([]T, int, error) {
+	from, to := 0, len(items)
+	keepFirst := false
+	if opts.Start > 0 {
+		from = opts.Start - 1
+	} else if selected >= 0 && selected < len(items) {
+		from = selected
+		keepFirst = true
+	}
+	if opts.End > 0 {
+		to = min(opts.End, len(items))
+	}
+
+	if from >= len(items) {
+		return nil, 0, fmt.Errorf("the playlist only has %d tracks", len(items))
+	}
+	if to <= from {
+		return nil, 0, fmt.Errorf("the end must not come before the start")
+	}
+
+	part := slices.Clone(items[from:to])
+	if opts.Shuffle {
+		shuffled := part
+		if keepFirst {
+			shuffled = part[1:]
+		}
+		rand.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+	}
+	return part, from, nil
+}
+
+// rangeNote describes the part of a playlist selectTracks picked.
+func rangeNote(from int, count int, total int, opts playlistOptions) string {
+	var note string
+	if count != total {
+		note += fmt.Sprintf("\nTracks **%d-%d** of **%d**.", from+1, from+count, total)
+	}
+	if opts.Shuffle {
+		note += fmt.Sprintf("\n%s Shuffled while adding.", IconShuffle)
+	}
+	return note
+}
@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgolink/v3/lavalink"
+
+	"jukeboxitus/src/bot/store"
+)
+
+var (
+	errPlaylistNotFound = errors.New("playlist not found")
+	errPlaylistExists   = errors.New("a playlist with that name already exists")
+)
+
+// SavedPlaylist is a playlist saved with /playlist. Its tracks keep their
+// encoded form, so playing it does not search them again.
+type SavedPlaylist struct {
+	Name      string           `json:"name"`
+	CreatorID string           `json:"creatorId"`
+	Tracks    []lavalink.Track `json:"tracks"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+// PlaylistStore keeps the saved playlists of users and guilds, by owner and
+// then by lowercased name. Owners are "user:<id>" or "guild:<id>", see
+// userOwner and guildOwner.
+type PlaylistStore struct {
+	file      *store.JSONFile[map[string]map[string]SavedPlaylist]
+	mu        sync.RWMutex
+	playlists map[string]map[string]SavedPlaylist
+}
+
+func userOwner(userID string) string {
+	return "user:" + userID
+}
+
+func guildOwner(guildID string) string {
+	return "guild:" + guildID
+}
+
+// NewPlaylistStore loads the saved playlists from dataDir.
+func NewPlaylistStore(dataDir string) (*PlaylistStore, error) {
+	file := store.NewJSONFile[map[string]map[string]SavedPlaylist](dataDir, "playlists.json")
+	playlists, err := file.Load()
+	if err != nil {
+		return nil, err
+	}
+	if playlists == nil {
+		playlists = make(map[string]map[string]SavedPlaylist)
+	}
+	return &PlaylistStore{file: file, playlists: playlists}, nil
+}
+
+func (s *PlaylistStore) Get(owner string, name string) (SavedPlaylist, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	playlist, ok := s.playlists[owner][strings.ToLower(name)]
+	return playlist, ok
+}
+
+// List returns the playlists of an owner sorted by name.
+func (s *PlaylistStore) List(owner string) []SavedPlaylist {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	playlists := make([]SavedPlaylist, 0, len(s.playlists[owner]))
+	for _, playlist := range s.playlists[owner] {
+		playlists = append(playlists, playlist)
+	}
+	slices.SortFunc(playlists, func(a, b SavedPlaylist) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return playlists
+}
+
+// Create adds a new playlist and persists all of them.
+func (s *PlaylistStore) Create(owner string, playlist SavedPlaylist) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(playlist.Name)
+	if _, ok := s.playlists[owner][key]; ok {
+		return errPlaylistExists
+	}
+	if s.playlists[owner] == nil {
+		s.playlists[owner] = make(map[string]SavedPlaylist)
+	}
+	playlist.UpdatedAt = time.Now()
+	s.playlists[owner][key] = playlist
+	return s.file.Save(s.playlists)
+}
+
+// Update changes a playlist and persists all of them. Nothing is saved when
+// update fails.
+func (s *PlaylistStore) Update(owner string, name string, update func(playlist *SavedPlaylist) error) (SavedPlaylist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(name)
+	playlist, ok := s.playlists[owner][key]
+	if !ok {
+		return playlist, errPlaylistNotFound
+	}
+	playlist.Tracks = slices.Clone(playlist.Tracks)
+	if err := update(&playlist); err != nil {
+		return playlist, err
+	}
+	playlist.UpdatedAt = time.Now()
+	s.playlists[owner][key] = playlist
+	return playlist, s.file.Save(s.playlists)
+}
+
+func (s *PlaylistStore) Delete(owner string, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.ToLower(name)
+	if _, ok := s.playlists[owner][key]; !ok {
+		return errPlaylistNotFound
+	}
+	delete(s.playlists[owner], key)
+	return s.file.Save(s.playlists)
+}
@@ -2,6 +2,8 @@ package bot
 
 import (
 	"math/rand"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/disgoorg/disgolink/v3/lavalink"
@@ -32,22 +34,38 @@ func (q QueueType) String() string {
 	}
 }
 
+// Queue is shared between command handlers, Lavalink events and background
+// loading, so Tracks must only be accessed through its methods.
 type Queue struct {
+	mu     sync.Mutex
 	Tracks []lavalink.Track
 	Type   QueueType
 }
 
 func (q *Queue) Shuffle() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	rand.Shuffle(len(q.Tracks), func(i, j int) {
 		q.Tracks[i], q.Tracks[j] = q.Tracks[j], q.Tracks[i]
 	})
 }
 
 func (q *Queue) Add(track ...lavalink.Track) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	q.Tracks = append(q.Tracks, track...)
 }
 
+// AddFront inserts tracks at the front of the queue, keeping their order.
+func (q *Queue) AddFront(track ...lavalink.Track) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.Tracks = slices.Insert(q.Tracks, 0, track...)
+}
+
 func (q *Queue) Next() (lavalink.Track, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	if len(q.Tracks) == 0 {
 		return lavalink.Track{}, false
 	}
@@ -56,15 +74,55 @@ func (q *Queue) Next() (lavalink.Track, bool) {
 	return track, true
 }
 
+func (q *Queue) Peek() (lavalink.Track, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.Tracks) == 0 {
+		return lavalink.Track{}, false
+	}
+	return q.Tracks[0], true
+}
+
+// ReplaceFront swaps the first track for replacement, unless the queue moved
+// on and old is no longer first.
+func (q *Queue) ReplaceFront(old lavalink.Track, replacement lavalink.Track) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.Tracks) == 0 || q.Tracks[0].Encoded != old.Encoded ||
+		string(q.Tracks[0].UserData) != string(old.UserData) || q.Tracks[0].Info.Title != old.Info.Title {
+		return false
+	}
+	q.Tracks[0] = replacement
+	return true
+}
+
 func (q *Queue) Clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	q.Tracks = make([]lavalink.Track, 0)
 }
 
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.Tracks)
+}
+
+// List returns a copy of the queued tracks.
+func (q *Queue) List() []lavalink.Track {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Clone(q.Tracks)
+}
+
 type QueueManager struct {
+	mu     sync.Mutex
 	Queues map[string]*Queue
 }
 
 func (q *QueueManager) Get(guildID string) *Queue {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	queue, ok := q.Queues[guildID]
 	if !ok {
 		queue = &Queue{
@@ -77,5 +135,7 @@ func (q *QueueManager) Get(guildID string) *Queue {
 }
 
 func (q *QueueManager) Delete(guildID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
 	delete(q.Queues, guildID)
 }
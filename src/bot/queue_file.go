@@ -0,0 +1,303 @@
+package bot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgolink/v3/lavalink"
+)
+
+// Queue files are exported and imported by /queue export and /queue import.
+const (
+	queueFormatM3U  = "m3u"
+	queueFormatJSON = "json"
+	queueFormatText = "txt"
+)
+
+// maxQueueFileSize is the largest file /queue import reads.
+const maxQueueFileSize = 1 << 20
+
+// m3uEncodedPrefix marks the line holding a track's encoded form in an M3U
+// file. Players ignore it like any other comment.
+const m3uEncodedPrefix = "#JUKEBOXITUS-TRACK:"
+
+var fileClient = &http.Client{Timeout: 15 * time.Second}
+
+// queueFileTrack is a track as written to a queue file.
+type queueFileTrack struct {
+	Title    string `json:"title"`
+	Author   string `json:"author,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Duration int64  `json:"durationMs,omitempty"`
+	Encoded  string `json:"encoded,omitempty"`
+}
+
+func toQueueFileTrack(track lavalink.Track) queueFileTrack {
+	info := trackInfo(track)
+	fileTrack := queueFileTrack{
+		Title:    info.Title,
+		Author:   info.Author,
+		Duration: info.Length.Milliseconds(),
+		Encoded:  track.Encoded,
+	}
+	if info.URI != nil {
+		fileTrack.URI = *info.URI
+	}
+	return fileTrack
+}
+
+// identifier returns what loads the track again when it has no encoded form.
+func (t queueFileTrack) identifier(settings Settings) string {
+	if t.URI != "" {
+		return t.URI
+	}
+	query := t.Title
+	if t.Author != "" {
+		query = t.Author + " - " + t.Title
+	}
+	return applySearchType(settings.SearchType, query)
+}
+
+// toTrack turns a track read from a file into a queueable track. Tracks with
+// an encoded form are played as they are, the others are loaded from their
+// URI or searched shortly before they play.
+func (t queueFileTrack) toTrack(settings Settings) lavalink.Track {
+	info := lavalink.TrackInfo{
+		Title:  t.Title,
+		Author: t.Author,
+		Length: lavalink.Duration(t.Duration),
+	}
+	if t.URI != "" {
+		uri := t.URI
+		info.URI = &uri
+	}
+	if t.Encoded != "" {
+		return lavalink.Track{Encoded: t.Encoded, Info: info}
+	}
+	return newPlaceholder(info, t.identifier(settings))
+}
+
+// writeQueueFile writes tracks in the given format and returns how many were
+// written. M3U and text files only hold tracks with a link.
+func writeQueueFile(w io.Writer, format string, tracks []queueFileTrack) (int, error) {
+	switch format {
+	case queueFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return len(tracks), encoder.Encode(tracks)
+
+	case queueFormatText:
+		bw := bufio.NewWriter(w)
+		written := 0
+		for _, track := range tracks {
+			if track.URI != "" {
+				fmt.Fprintln(bw, track.URI)
+				written++
+			}
+		}
+		return written, bw.Flush()
+
+	default:
+		bw := bufio.NewWriter(w)
+		written := 0
+		fmt.Fprintln(bw, "#EXTM3U")
+		for _, track := range tracks {
+			if track.URI == "" {
+				continue
+			}
+			written++
+			title := track.Title
+			if track.Author != "" {
+				title = track.Author + " - " + track.Title
+			}
+			fmt.Fprintf(bw, "#EXTINF:%d,%s\n", track.Duration/1000, title)
+			if track.Encoded != "" {
+				fmt.Fprintln(bw, m3uEncodedPrefix+track.Encoded)
+			}
+			fmt.Fprintln(bw, track.URI)
+		}
+		return written, bw.Flush()
+	}
+}
+
+// decodeQueueFile checks the encoded tracks of a file with the node and takes
+// their title and link from the decoded track, since the rest of the file may
+// not match them. Tracks that fail to decode or have no web link lose their
+// encoded form and are loaded from their link or searched instead.
+func (b *Bot) decodeQueueFile(tracks []queueFileTrack) {
+	var (
+		indexes []int
+		encoded []string
+	)
+	for i, track := range tracks {
+		if track.Encoded != "" {
+			indexes = append(indexes, i)
+			encoded = append(encoded, track.Encoded)
+		}
+	}
+	if len(encoded) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.Config().LoadTracksTimeout())
+	defer cancel()
+	node := b.Lavalink.BestNode()
+	decoded, err := node.DecodeTracks(ctx, encoded)
+	if err != nil || len(decoded) != len(encoded) {
+		// A single broken track fails the whole batch, so they are
+		// decoded one by one to keep the others
+		decoded = make([]lavalink.Track, len(encoded))
+		for i, track := range encoded {
+			if one, err := node.DecodeTrack(ctx, track); err == nil {
+				decoded[i] = *one
+			}
+		}
+	}
+	for i, index := range indexes {
+		// Tracks of local files or other sources without a web link are
+		// searched like entries without an encoded form
+		if uri := decoded[i].Info.URI; decoded[i].Encoded == "" || uri == nil || !urlPattern.MatchString(*uri) {
+			tracks[index].Encoded = ""
+			continue
+		}
+		tracks[index] = toQueueFileTrack(decoded[i])
+	}
+}
+
+// readQueueFile parses a queue file. The format is taken from the file name
+// and otherwise guessed from the content. Only web links are kept as links,
+// so local files and other schemes are never handed to the node.
+func readQueueFile(name string, data []byte) ([]queueFileTrack, error) {
+	var tracks []queueFileTrack
+	trimmed := bytes.TrimSpace(data)
+	switch ext := strings.ToLower(path.Ext(name)); {
+	case ext == ".json" || bytes.HasPrefix(trimmed, []byte("[")):
+		if err := json.Unmarshal(trimmed, &tracks); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+
+	case ext == ".m3u" || ext == ".m3u8" || bytes.HasPrefix(trimmed, []byte("#EXTM3U")):
+		tracks = readM3U(trimmed)
+
+	default:
+		tracks = readTextList(trimmed)
+	}
+
+	for i, track := range tracks {
+		if !urlPattern.MatchString(track.URI) {
+			tracks[i].URI = ""
+		}
+	}
+	return tracks, nil
+}
+
+// readM3U reads the entries of an M3U playlist, with the title and duration
+// of their #EXTINF line.
+func readM3U(data []byte) []queueFileTrack {
+	var (
+		tracks  []queueFileTrack
+		current queueFileTrack
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, maxQueueFileSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "#EXTINF:"):
+			duration, title, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			if seconds, err := strconv.ParseInt(strings.TrimSpace(duration), 10, 64); err == nil && seconds > 0 {
+				current.Duration = seconds * 1000
+			}
+			current.Author, current.Title = splitTitle(strings.TrimSpace(title))
+
+		case strings.HasPrefix(line, m3uEncodedPrefix):
+			current.Encoded = strings.TrimPrefix(line, m3uEncodedPrefix)
+
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+
+		default:
+			// Entries that are no web links, such as local files, are
+			// searched by their file name
+			if urlPattern.MatchString(line) {
+				current.URI = line
+			}
+			if current.Title == "" {
+				current.Author, current.Title = splitTitle(fileTitle(line))
+			}
+			tracks = append(tracks, current)
+			current = queueFileTrack{}
+		}
+	}
+	return tracks
+}
+
+// readTextList reads one link or search query per line, skipping blank lines
+// and # comments.
+func readTextList(data []byte) []queueFileTrack {
+	var tracks []queueFileTrack
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		track := queueFileTrack{Title: line}
+		if urlPattern.MatchString(line) {
+			track.URI = line
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks
+}
+
+// fileTitle returns the name of a file entry without its directory and
+// extension, or the entry itself when it is a web link.
+func fileTitle(entry string) string {
+	if urlPattern.MatchString(entry) {
+		return entry
+	}
+	name := path.Base(strings.ReplaceAll(strings.TrimPrefix(entry, "file://"), "\\", "/"))
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// splitTitle splits an "Author - Title" display title.
+func splitTitle(title string) (string, string) {
+	if author, rest, ok := strings.Cut(title, " - "); ok {
+		return strings.TrimSpace(author), strings.TrimSpace(rest)
+	}
+	return "", title
+}
+
+// downloadAttachment reads an uploaded file of at most maxSize bytes.
+func downloadAttachment(ctx context.Context, url string, maxSize int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := fileClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("the file is larger than %d KB", maxSize>>10)
+	}
+	return data, nil
+}
@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteQueueFile(t *testing.T) {
+	tracks := []queueFileTrack{
+		{Title: "Never Gonna Give You Up", Author: "Rick Astley", URI: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", Duration: 213000, Encoded: "QAAA"},
+		{Title: "Wake Me Up", Author: "Avicii"},
+		{Title: "Come Together", URI: "https://soundcloud.com/the-beatles/come-together"},
+	}
+	tests := []struct {
+		format  string
+		written int
+		want    string
+	}{
+		{queueFormatM3U, 2, "#EXTM3U\n" +
+			"#EXTINF:213,Rick Astley - Never Gonna Give You Up\n" +
+			"#JUKEBOXITUS-TRACK:QAAA\n" +
+			"https://www.youtube.com/watch?v=dQw4w9WgXcQ\n" +
+			"#EXTINF:0,Come Together\n" +
+			"https://soundcloud.com/the-beatles/come-together\n"},
+		{queueFormatText, 2, "https://www.youtube.com/watch?v=dQw4w9WgXcQ\nhttps://soundcloud.com/the-beatles/come-together\n"},
+		{queueFormatJSON, 3, ""},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		written, err := writeQueueFile(&buf, test.format, tracks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written != test.written {
+			t.Errorf("%s: wrote %d tracks, want %d", test.format, written, test.written)
+		}
+		if test.want != "" && buf.String() != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.format, buf.String(), test.want)
+		}
+
+		// Whatever is written is read back the same
+		read, err := readQueueFile("queue."+test.format, buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(read) != written {
+			t.Errorf("%s: read %d tracks back, want %d", test.format, len(read), written)
+		}
+		if test.format != queueFormatText && !reflect.DeepEqual(read[0], tracks[0]) {
+			t.Errorf("%s: read back %+v, want %+v", test.format, read[0], tracks[0])
+		}
+	}
+}
+
+func TestReadTextList(t *testing.T) {
+	got := readTextList([]byte(strings.Join([]string{
+		"# my queue",
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"",
+		"  Avicii - Wake Me Up  ",
+	}, "\n")))
+	want := []queueFileTrack{
+		{Title: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", URI: "https://www.youtube.com/watch?v=dQw4w9WgXcQ"},
+		{Title: "Avicii - Wake Me Up"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestReadQueueFileLinks(t *testing.T) {
+	m3u := strings.Join([]string{
+		"#EXTM3U",
+		"#EXTINF:213,Rick Astley - Never Gonna Give You Up",
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"/home/me/Music/Avicii - Wake Me Up.mp3",
+		`C:\Music\Daft Punk - One More Time.flac`,
+		"#EXTINF:200,Come Together",
+		"file:///etc/passwd",
+	}, "\n")
+	got, err := readQueueFile("queue.m3u", []byte(m3u))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []queueFileTrack{
+		{Title: "Never Gonna Give You Up", Author: "Rick Astley", URI: "https://www.youtube.com/watch?v=dQw4w9WgXcQ", Duration: 213000},
+		{Title: "Wake Me Up", Author: "Avicii"},
+		{Title: "One More Time", Author: "Daft Punk"},
+		{Title: "Come Together", Duration: 200000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("m3u: got %+v, want %+v", got, want)
+	}
+
+	data := `[{"title": "Wake Me Up", "uri": "file:///home/me/Music/wake.mp3"}, {"title": "Come Together", "uri": "/dev/zero"}]`
+	got, err = readQueueFile("queue.json", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []queueFileTrack{{Title: "Wake Me Up"}, {Title: "Come Together"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("json: got %+v, want %+v", got, want)
+	}
+}
@@ -1,20 +1,22 @@
 package bot
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/disgoorg/snowflake/v2"
-
 	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/snowflake/v2"
 )
 
 func (b *Bot) Shuffle(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
 	queue := b.Queues.Get(event.GuildID)
 
 	// Error: No tracks to shuffle
-	if queue == nil || len(queue.Tracks) == 0 {
+	if queue == nil || queue.Len() == 0 {
 		return b.SendResponse(
 			event.Interaction,
 			"Queue Error",
@@ -29,7 +31,7 @@ func (b *Bot) Shuffle(event *discordgo.InteractionCreate, data discordgo.Applica
 	return b.SendResponse(
 		event.Interaction,
 		"Queue Shuffled",
-		fmt.Sprintf("%s Successfully shuffled **%d** tracks!", IconShuffle, len(queue.Tracks)),
+		fmt.Sprintf("%s Successfully shuffled **%d** tracks!", IconShuffle, queue.Len()),
 		ColorSuccess,
 	)
 }
@@ -49,25 +51,22 @@ func (b *Bot) Skip(event *discordgo.InteractionCreate, data discordgo.Applicatio
 			fmt.Sprintf("%s No queue found for this server.", IconEmpty), ColorError)
 	}
 
-	// 3. Try to get the next track
-	nextTrack, ok := queue.Next()
+	// 3. Play the next track, loading it first if it is a placeholder
+	nextTrack, ok, err := b.playNext(player, queue)
 	if !ok {
 		return b.SendResponse(event.Interaction, "End of Queue",
 			fmt.Sprintf("%s No more tracks to skip to.", IconEmpty), ColorWarning)
 	}
-
-	// 4. Update the player with the new track
-	err := player.Update(context.Background(), lavalink.WithTrack(nextTrack))
 	if err != nil {
 		return b.SendResponse(event.Interaction, "Playback Error",
 			fmt.Sprintf("%s Error while playing the next track: `%s`", IconError, err), ColorError)
 	}
 
-	// 5. Success Card
+	// 4. Success Card
 	return b.SendResponse(
 		event.Interaction,
 		"Track Skipped",
-		fmt.Sprintf("%s Skipped to: **[`%s`](<%s>)**", IconSkip, nextTrack.Info.Title, *nextTrack.Info.URI),
+		fmt.Sprintf("%s Skipped to: **%s**", IconSkip, trackLink(nextTrack)),
 		ColorSuccess,
 	)
 }
@@ -115,7 +114,7 @@ func (b *Bot) ClearQueue(event *discordgo.InteractionCreate, data discordgo.Appl
 
 	// 2. Logic: Clear the tracks
 	// We can check the count before clearing to give a more detailed message
-	count := len(queue.Tracks)
+	count := queue.Len()
 	queue.Clear()
 
 	// 3. Success Card
@@ -127,38 +126,252 @@ func (b *Bot) ClearQueue(event *discordgo.InteractionCreate, data discordgo.Appl
 	)
 }
 
+// queuePageSize is how many tracks a page of /queue view shows.
+const queuePageSize = 10
+
 func (b *Bot) Queue(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
-	queue := b.Queues.Get(event.GuildID)
+	subcommand := data.Options[0]
 
-	// 1. Error: No queue found
-	if queue == nil {
-		return b.SendResponse(event.Interaction, "Queue Status",
-			fmt.Sprintf("%s No active player found.", IconError), ColorError)
+	switch subcommand.Name {
+	case "export":
+		return b.exportQueue(event, subcommand.Options)
+	case "import":
+		return b.importQueue(event, data, subcommand.Options)
+	default:
+		return b.viewQueue(event)
 	}
+}
 
-	// 2. Case: Empty Queue
-	if len(queue.Tracks) == 0 {
+func (b *Bot) viewQueue(event *discordgo.InteractionCreate) error {
+	embed, components, ok := b.queuePage(event.GuildID, 0)
+	if !ok {
 		return b.SendResponse(event.Interaction, "Queue Status",
 			fmt.Sprintf("%s The queue is currently empty.", IconEmpty), ColorDefault)
 	}
 
-	// 3. Logic: Build the track list string
-	var tracks string
-	for i, track := range queue.Tracks {
-		// Stop adding if we approach the embed description limit (4096)
-		line := fmt.Sprintf("**%d.** [`%s`](<%s>)\n", i+1, track.Info.Title, *track.Info.URI)
-		if len(tracks)+len(line) > 4000 {
-			tracks += "...and more"
-			break
+	return b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+// QueuePage handles the navigation buttons of /queue view. Their custom ID is
+// "queue:<button>:<page>".
+func (b *Bot) QueuePage(event *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) error {
+	page, err := strconv.Atoi(data.CustomID[strings.LastIndex(data.CustomID, ":")+1:])
+	if err != nil {
+		return err
+	}
+
+	embed, components, ok := b.queuePage(event.GuildID, page)
+	if !ok {
+		return b.UpdateResponse(event.Interaction, "Queue Status",
+			fmt.Sprintf("%s The queue is currently empty.", IconEmpty), "", ColorDefault)
+	}
+
+	return b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+// queuePage renders a page of the queue with its navigation buttons. The page
+// is clamped, since the queue may have changed since the buttons were sent.
+// It reports false when nothing is playing or queued.
+func (b *Bot) queuePage(guildID string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, bool) {
+	queue := b.Queues.Get(guildID)
+	tracks := queue.List()
+	player := b.Lavalink.ExistingPlayer(snowflake.MustParse(guildID))
+	var current *lavalink.Track
+	if player != nil {
+		current = player.Track()
+	}
+	if current == nil && len(tracks) == 0 {
+		return nil, nil, false
+	}
+
+	pages := max((len(tracks)+queuePageSize-1)/queuePageSize, 1)
+	page = min(max(page, 0), pages-1)
+
+	filters := "None"
+	if player != nil {
+		if names := activeFilters(player.Filters()); len(names) > 0 {
+			filters = strings.Join(names, ", ")
 		}
-		tracks += line
 	}
 
-	// 4. Success Card
-	return b.SendResponse(
-		event.Interaction,
-		fmt.Sprintf("%s Current Queue (%s)", IconQueue, queue.Type.String()),
-		tracks,
-		ColorDefault,
+	var (
+		sb        strings.Builder
+		remaining lavalink.Duration
+		live      bool
 	)
+	fmt.Fprintf(&sb, "%s Loop: **%s** • %s Filters: **%s**\n\n", IconRepeat, queue.Type.String(), IconBass, filters)
+	if current != nil {
+		fmt.Fprintf(&sb, "%s **Now Playing:** %s `%s / %s`%s\n\n", IconPlay, trackLink(*current),
+			formatPosition(player.Position()), formatLength(*current), requesterMention(*current))
+		if current.Info.IsStream {
+			live = true
+		} else {
+			remaining += max(current.Info.Length-player.Position(), 0)
+		}
+	}
+
+	for _, track := range tracks {
+		if track.Info.IsStream {
+			live = true
+		} else {
+			remaining += track.Info.Length
+		}
+	}
+
+	if len(tracks) == 0 {
+		fmt.Fprintf(&sb, "%s Nothing else is queued.", IconEmpty)
+	}
+	for i := page * queuePageSize; i < min((page+1)*queuePageSize, len(tracks)); i++ {
+		fmt.Fprintf(&sb, "**%d.** %s `%s`%s\n", i+1, trackLink(tracks[i]), formatLength(tracks[i]), requesterMention(tracks[i]))
+	}
+
+	left := formatTotal(remaining)
+	if live {
+		left += " + live"
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s Current Queue", IconQueue),
+		Description: sb.String(),
+		Color:       ColorDefault,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Page %d/%d • %d tracks • %s left", page+1, pages, len(tracks), left),
+		},
+	}
+	if current != nil {
+		if artwork := trackArtwork(*current); artwork != "" {
+			embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: artwork}
+		}
+	}
+
+	if pages == 1 {
+		return embed, []discordgo.MessageComponent{}, true
+	}
+	return embed, pageButtons("queue", page, pages), true
+}
+
+// requesterMention renders who queued track, if known.
+func requesterMention(track lavalink.Track) string {
+	if id := trackData(track).RequesterID; id != "" {
+		return fmt.Sprintf(" • <@%s>", id)
+	}
+	return ""
+}
+
+// formatTotal renders a duration that may be longer than an hour.
+func formatTotal(d lavalink.Duration) string {
+	if d.Hours() > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", d.Hours(), d.MinutesPart(), d.SecondsPart())
+	}
+	return formatPosition(d)
+}
+
+// exportQueue sends the current track and the queue as a file.
+func (b *Bot) exportQueue(event *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	format := queueFormatM3U
+	if opt, ok := optionMap(options)["format"]; ok {
+		format = opt.StringValue()
+	}
+
+	var tracks []queueFileTrack
+	if _, current := b.currentTrack(event.GuildID); current != nil {
+		tracks = append(tracks, toQueueFileTrack(savedTrack(*current)))
+	}
+	for _, track := range b.Queues.Get(event.GuildID).List() {
+		tracks = append(tracks, toQueueFileTrack(track))
+	}
+	if len(tracks) == 0 {
+		return b.SendResponse(event.Interaction, "Queue Export",
+			fmt.Sprintf("%s The queue is empty, there is nothing to export.", IconEmpty), ColorDefault)
+	}
+
+	var buf bytes.Buffer
+	written, err := writeQueueFile(&buf, format, tracks)
+	if err != nil {
+		return err
+	}
+	description := fmt.Sprintf("%s Exported **%d** tracks. Load them again with `/queue import`.", IconSuccess, written)
+	if skipped := len(tracks) - written; skipped > 0 {
+		description += fmt.Sprintf("\n%s **%d** tracks without a link were left out, export as JSON to keep them.", IconWarning, skipped)
+	}
+
+	contentTypes := map[string]string{
+		queueFormatM3U:  "audio/x-mpegurl",
+		queueFormatJSON: "application/json",
+		queueFormatText: "text/plain",
+	}
+	return b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       "Queue Exported",
+				Description: description,
+				Color:       ColorSuccess,
+			}},
+			Files: []*discordgo.File{{
+				Name:        "queue." + format,
+				ContentType: contentTypes[format],
+				Reader:      &buf,
+			}},
+		},
+	})
+}
+
+// importQueue adds the tracks of an uploaded queue file to the queue.
+func (b *Bot) importQueue(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	var attachment *discordgo.MessageAttachment
+	if opt, ok := optionMap(options)["file"]; ok && data.Resolved != nil {
+		attachment = data.Resolved.Attachments[opt.StringValue()]
+	}
+	if attachment == nil {
+		return b.SendResponse(event.Interaction, "Import Error",
+			fmt.Sprintf("%s Attach an M3U, JSON or text file to import.", IconError), ColorError)
+	}
+
+	return b.playWith(event, playModeQueue, false, func() (*loadedTracks, error) {
+		return b.loadQueueFile(attachment, b.Settings(event.GuildID))
+	})
+}
+
+// loadQueueFile reads the tracks of an uploaded queue file.
+func (b *Bot) loadQueueFile(attachment *discordgo.MessageAttachment, settings Settings) (*loadedTracks, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.Config().LoadTracksTimeout())
+	defer cancel()
+
+	content, err := downloadAttachment(ctx, attachment.URL, maxQueueFileSize)
+	if err != nil {
+		return nil, &loadError{Title: "Import Error", Icon: IconError, Color: ColorError,
+			Message: fmt.Sprintf("Could not read `%s`: `%s`", attachment.Filename, err)}
+	}
+	fileTracks, err := readQueueFile(attachment.Filename, content)
+	if err != nil {
+		return nil, &loadError{Title: "Import Error", Icon: IconError, Color: ColorError,
+			Message: fmt.Sprintf("Could not read `%s`: `%s`", attachment.Filename, err)}
+	}
+	b.decodeQueueFile(fileTracks)
+
+	loaded := &loadedTracks{From: fmt.Sprintf("file: `%s`", attachment.Filename)}
+	for _, fileTrack := range fileTracks {
+		if fileTrack.Encoded == "" && fileTrack.URI == "" && fileTrack.Title == "" {
+			continue
+		}
+		loaded.Tracks = append(loaded.Tracks, fileTrack.toTrack(settings))
+	}
+	if len(loaded.Tracks) == 0 {
+		return nil, &loadError{Title: "Import Error", Icon: IconEmpty, Color: ColorDefault,
+			Message: fmt.Sprintf("No tracks were found in `%s`.", attachment.Filename)}
+	}
+	loaded.limit(b.Config().MaxPlaylistTracks())
+	return loaded, nil
 }
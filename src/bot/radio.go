@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/log"
+
+	bot_config "jukeboxitus/src/bot/config"
+	"jukeboxitus/src/bot/icy"
+)
+
+// streamTitleInterval is how often the title of a playing radio stream is
+// checked.
+const streamTitleInterval = 20 * time.Second
+
+var icyClient = &http.Client{Timeout: 15 * time.Second}
+
+// streamTitles follows what is on air on the radio streams guilds are
+// playing. Lavalink does not report it, so the stream's ICY metadata is read
+// directly.
+type streamTitles struct {
+	mu      sync.Mutex
+	watches map[string]*streamWatch
+}
+
+type streamWatch struct {
+	cancel context.CancelFunc
+	title  string
+}
+
+func (b *Bot) Radio(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	config := b.Config()
+	if len(config.Radio) == 0 {
+		return b.SendResponse(event.Interaction, "Radio",
+			fmt.Sprintf("%s No radio stations are configured.", IconEmpty), ColorDefault)
+	}
+
+	opt, ok := optionMap(data.Options)["station"]
+	if !ok {
+		var sb strings.Builder
+		for _, station := range config.Radio {
+			fmt.Fprintf(&sb, "%s **%s**\n", IconRadio, station.Name)
+		}
+		return b.SendResponse(event.Interaction, "Radio Stations", sb.String(), ColorDefault)
+	}
+
+	station, ok := config.Station(opt.StringValue())
+	if !ok {
+		return b.SendResponse(event.Interaction, "Radio Error",
+			fmt.Sprintf("%s Unknown station `%s`.", IconError, opt.StringValue()), ColorError)
+	}
+
+	// A stream never ends, so it interrupts the current track instead of
+	// waiting behind it
+	return b.playWith(event, playModeNow, true, func() (*loadedTracks, error) {
+		return b.loadStation(station, b.Settings(event.GuildID))
+	})
+}
+
+// loadStation loads a station's stream, titled with the station's name.
+func (b *Bot) loadStation(station bot_config.RadioStation, settings Settings) (*loadedTracks, error) {
+	loaded, err := b.loadTracks(station.URL, settings, playlistOptions{})
+	if err != nil {
+		return nil, err
+	}
+	track := withTrackData(loaded.Tracks[0], TrackData{Title: station.Name})
+	return &loadedTracks{Tracks: []lavalink.Track{track}, ArtworkURL: loaded.ArtworkURL}, nil
+}
+
+func (b *Bot) RadioAutocomplete(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	typed := ""
+	for _, opt := range data.Options {
+		if opt.Focused {
+			typed = strings.ToLower(opt.StringValue())
+		}
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, station := range b.Config().Radio {
+		if !strings.Contains(strings.ToLower(station.Name), typed) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  station.Name,
+			Value: station.Name,
+		})
+	}
+	if len(choices) > 25 {
+		choices = choices[:25]
+	}
+
+	return b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
+
+// watchStreamTitle follows the stream title of track while it plays, if it
+// is an HTTP stream. Title changes are announced like new tracks.
+func (b *Bot) watchStreamTitle(guildID string, track lavalink.Track) {
+	b.stopStreamTitle(guildID)
+	if !track.Info.IsStream || track.Info.SourceName != "http" || track.Info.URI == nil {
+		return
+	}
+	streamURL := *track.Info.URI
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.streams.mu.Lock()
+	if b.streams.watches == nil {
+		b.streams.watches = make(map[string]*streamWatch)
+	}
+	watch := &streamWatch{cancel: cancel}
+	b.streams.watches[guildID] = watch
+	b.streams.mu.Unlock()
+
+	go func() {
+		for {
+			title, err := icy.StreamTitle(ctx, icyClient, streamURL)
+			if errors.Is(err, icy.ErrNoMetadata) {
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				log.Debug("failed to read stream title: ", err)
+			}
+			if err == nil && title != "" && b.setStreamTitle(watch, title) {
+				b.announceStreamTitle(guildID, track, title)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(streamTitleInterval):
+			}
+		}
+	}()
+}
+
+// setStreamTitle records title and reports whether it changed.
+func (b *Bot) setStreamTitle(watch *streamWatch, title string) bool {
+	b.streams.mu.Lock()
+	defer b.streams.mu.Unlock()
+	if watch.title == title {
+		return false
+	}
+	watch.title = title
+	return true
+}
+
+func (b *Bot) stopStreamTitle(guildID string) {
+	b.streams.mu.Lock()
+	defer b.streams.mu.Unlock()
+	if watch, ok := b.streams.watches[guildID]; ok {
+		watch.cancel()
+		delete(b.streams.watches, guildID)
+	}
+}
+
+// streamTitle returns what is on air on the stream a guild is playing.
+func (b *Bot) streamTitle(guildID string) string {
+	b.streams.mu.Lock()
+	defer b.streams.mu.Unlock()
+	if watch, ok := b.streams.watches[guildID]; ok {
+		return watch.title
+	}
+	return ""
+}
+
+func (b *Bot) announceStreamTitle(guildID string, track lavalink.Track, title string) {
+	b.announce(guildID, &discordgo.MessageEmbed{
+		Title:       "On Air",
+		Description: fmt.Sprintf("%s **%s**\n%s", IconRadio, title, trackLink(track)),
+		Color:       ColorDefault,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Jukeboxitus Music",
+		},
+	})
+}
+
+// formatLength renders the length of a track, or LIVE for streams.
+func formatLength(track lavalink.Track) string {
+	if track.Info.IsStream {
+		return IconLive + " LIVE"
+	}
+	return formatPosition(track.Info.Length)
+}
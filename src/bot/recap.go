@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+
+	"jukeboxitus/src/bot/chart"
+)
+
+// recapChart styles the listening chart of /recap after Discord's dark theme.
+var recapChart = chart.Bars{
+	Width:      800,
+	Height:     300,
+	Unit:       "h",
+	Background: color.RGBA{0x2b, 0x2d, 0x31, 0xff},
+	Grid:       color.RGBA{0x3f, 0x41, 0x47, 0xff},
+	Fill:       color.RGBA{0x58, 0x65, 0xf2, 0xff},
+	Text:       color.RGBA{0xb5, 0xba, 0xc1, 0xff},
+}
+
+// recapPeriod is the month or year a recap covers so far, cut into the days
+// or months its chart shows.
+type recapPeriod struct {
+	title   string
+	start   time.Time
+	buckets int
+	bucket  func(t time.Time) int
+	label   func(bucket int) string
+	unit    string
+}
+
+func newRecapPeriod(period string, now time.Time) recapPeriod {
+	if period == "year" {
+		return recapPeriod{
+			title:   fmt.Sprintf("%d Recap", now.Year()),
+			start:   time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location()),
+			buckets: 12,
+			bucket:  func(t time.Time) int { return int(t.Month()) - 1 },
+			label:   func(bucket int) string { return strconv.Itoa(bucket + 1) },
+			unit:    "month",
+		}
+	}
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return recapPeriod{
+		title:   fmt.Sprintf("%s %d Recap", now.Month(), now.Year()),
+		start:   start,
+		buckets: start.AddDate(0, 1, -1).Day(),
+		bucket:  func(t time.Time) int { return t.Day() - 1 },
+		label: func(bucket int) string {
+			// Every day doesn't fit under the bars
+			if day := bucket + 1; day == 1 || day%5 == 0 {
+				return strconv.Itoa(day)
+			}
+			return ""
+		},
+		unit: "day",
+	}
+}
+
+// Recap sums up what the server listened to this month or this year, with a
+// chart of the hours listened per day or month. Times are in the bot's time
+// zone.
+func (b *Bot) Recap(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	periodName := "month"
+	if opt, ok := optionMap(data.Options)["period"]; ok {
+		periodName = opt.StringValue()
+	}
+	period := newRecapPeriod(periodName, time.Now())
+
+	plays := countedPlays(b.History.Plays(event.GuildID, period.start))
+	if len(plays) == 0 {
+		return b.SendResponse(event.Interaction, period.title,
+			fmt.Sprintf("%s Nothing was played this %s yet.", IconEmpty, periodName), ColorDefault)
+	}
+
+	var (
+		total    lavalink.Duration
+		hours    [24]lavalink.Duration
+		weekdays [7]lavalink.Duration
+		buckets  = make([]lavalink.Duration, period.buckets)
+		skipped  []Play
+	)
+	for _, play := range plays {
+		started := play.StartedAt.Local()
+		total += play.Listened
+		hours[started.Hour()] += play.Listened
+		weekdays[started.Weekday()] += play.Listened
+		if bucket := period.bucket(started); bucket >= 0 && bucket < len(buckets) {
+			buckets[bucket] += play.Listened
+		}
+		if play.Reason == PlaySkipped {
+			skipped = append(skipped, play)
+		}
+	}
+	busiestHour := busiest(hours[:])
+	busiestDay := time.Weekday(busiest(weekdays[:]))
+
+	bars := make([]chart.Bar, len(buckets))
+	for i, listened := range buckets {
+		bars[i] = chart.Bar{Value: float64(listened) / float64(time.Hour/time.Millisecond), Label: period.label(i)}
+	}
+	listening := recapChart
+	listening.Bars = bars
+	var image bytes.Buffer
+	if err := listening.PNG(&image); err != nil {
+		return err
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Listening Time", Value: fmt.Sprintf("%.1f hours", float64(total)/float64(time.Hour/time.Millisecond)), Inline: true},
+		{Name: "Tracks Played", Value: strconv.Itoa(len(plays)), Inline: true},
+		{Name: "Busiest Time", Value: fmt.Sprintf("%ss, %02d:00–%02d:00", busiestDay, busiestHour, (busiestHour+1)%24), Inline: true},
+		{Name: "Top Tracks", Value: formatRanking(rankPlays(plays, playTrack), 5)},
+	}
+	if artists := rankPlays(plays, playArtist); len(artists) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Top Artists", Value: formatRanking(artists, 5)})
+	}
+	if djs := rankPlays(plays, playRequester); len(djs) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Top DJs", Value: formatRanking(djs, 3)})
+	}
+	if mostSkipped := rankPlays(skipped, playTrack); len(mostSkipped) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Most Skipped", Value: formatSkipped(mostSkipped[0])})
+	}
+	for _, field := range fields {
+		field.Value = fitLines(field.Value, 1024)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s %s", IconQueue, period.title),
+		Description: fmt.Sprintf("Hours listened per %s:", period.unit),
+		Color:       ColorDefault,
+		Fields:      fields,
+		Image:       &discordgo.MessageEmbedImage{URL: "attachment://recap.png"},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Jukeboxitus Music",
+		},
+	}
+	return b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Files: []*discordgo.File{{
+				Name:        "recap.png",
+				ContentType: "image/png",
+				Reader:      &image,
+			}},
+		},
+	})
+}
+
+// busiest returns the index of the longest duration, the first one on a tie.
+func busiest(durations []lavalink.Duration) int {
+	return slices.Index(durations, slices.Max(durations))
+}
+
+func formatSkipped(count playCount) string {
+	times := "times"
+	if count.plays == 1 {
+		times = "time"
+	}
+	return fmt.Sprintf("%s • skipped %d %s", count.label, count.plays, times)
+}
+
+// fitLines drops the last lines of s until it is at most size characters, so
+// no link is cut in half.
+func fitLines(s string, size int) string {
+	for len([]rune(s)) > size {
+		index := strings.LastIndex(strings.TrimRight(s, "\n"), "\n")
+		if index < 0 {
+			return truncate(s, size)
+		}
+		s = s[:index+1]
+	}
+	return s
+}
@@ -0,0 +1,174 @@
+package resolver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	appleMusicPattern  = regexp.MustCompile(`^https?://music\.apple\.com/([a-z]{2})/(album|song|playlist)/(?:[^/?]+/)?([\w.-]+)`)
+	appleSongIDPattern = regexp.MustCompile(`/(\d+)(?:\?|$)`)
+)
+
+// AppleMusic uses the public iTunes lookup API for songs and albums. The
+// lookup API knows nothing about playlists, so those are read from the song
+// links the playlist page lists in its Open Graph tags.
+type AppleMusic struct {
+	client *http.Client
+	// LookupURL and PageURL are replaced in tests.
+	LookupURL string
+	PageURL   string
+}
+
+func NewAppleMusic(client *http.Client) *AppleMusic {
+	return &AppleMusic{
+		client:    client,
+		LookupURL: "https://itunes.apple.com/lookup",
+		PageURL:   "https://music.apple.com",
+	}
+}
+
+func (a *AppleMusic) Name() string {
+	return "Apple Music"
+}
+
+func (a *AppleMusic) Match(link string) bool {
+	return appleMusicPattern.MatchString(link)
+}
+
+type itunesResult struct {
+	WrapperType    string `json:"wrapperType"`
+	Kind           string `json:"kind"`
+	TrackName      string `json:"trackName"`
+	ArtistName     string `json:"artistName"`
+	CollectionName string `json:"collectionName"`
+	TrackTimeMs    int64  `json:"trackTimeMillis"`
+	ArtworkURL100  string `json:"artworkUrl100"`
+}
+
+// artwork asks the image server for a bigger version of the 100px artwork.
+func (r itunesResult) artwork() string {
+	return strings.Replace(r.ArtworkURL100, "100x100bb", "600x600bb", 1)
+}
+
+func (a *AppleMusic) Resolve(ctx context.Context, link string) (*Result, error) {
+	match := appleMusicPattern.FindStringSubmatch(link)
+	if match == nil {
+		return nil, ErrUnsupported
+	}
+	country, kind, id := match[1], match[2], match[3]
+
+	// Album links to a single song carry its ID in the "i" parameter
+	if parsed, err := url.Parse(link); err == nil && kind == "album" {
+		if songID := parsed.Query().Get("i"); songID != "" {
+			kind, id = "song", songID
+		}
+	}
+
+	switch kind {
+	case "song":
+		results, err := a.lookup(ctx, country, []string{id}, false)
+		if err != nil {
+			return nil, err
+		}
+		return a.result("", "", results)
+	case "album":
+		results, err := a.lookup(ctx, country, []string{id}, true)
+		if err != nil {
+			return nil, err
+		}
+		name, artwork := "", ""
+		for _, r := range results {
+			if r.WrapperType == "collection" {
+				name, artwork = r.CollectionName, r.artwork()
+			}
+		}
+		return a.result(name, artwork, results)
+	default:
+		return a.resolvePlaylist(ctx, link, country)
+	}
+}
+
+func (a *AppleMusic) resolvePlaylist(ctx context.Context, link string, country string) (*Result, error) {
+	pageURL := a.PageURL + strings.TrimPrefix(link, "https://music.apple.com")
+	body, err := get(ctx, a.client, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	name, _ := doc.Find(`meta[property="og:title"]`).Attr("content")
+	artwork, _ := doc.Find(`meta[property="og:image"]`).Attr("content")
+
+	var ids []string
+	doc.Find(`meta[property="music:song"]`).Each(func(_ int, s *goquery.Selection) {
+		content, _ := s.Attr("content")
+		if match := appleSongIDPattern.FindStringSubmatch(content); match != nil {
+			ids = append(ids, match[1])
+		}
+	})
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("apple music playlist has no public tracks")
+	}
+
+	var results []itunesResult
+	for start := 0; start < len(ids); start += 100 {
+		batch, err := a.lookup(ctx, country, ids[start:min(start+100, len(ids))], false)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, batch...)
+	}
+	return a.result(name, artwork, results)
+}
+
+func (a *AppleMusic) lookup(ctx context.Context, country string, ids []string, songs bool) ([]itunesResult, error) {
+	query := url.Values{}
+	query.Set("id", strings.Join(ids, ","))
+	query.Set("country", country)
+	if songs {
+		query.Set("entity", "song")
+	}
+
+	var response struct {
+		Results []itunesResult `json:"results"`
+	}
+	if err := getJSON(ctx, a.client, a.LookupURL+"?"+query.Encode(), &response); err != nil {
+		return nil, err
+	}
+	return response.Results, nil
+}
+
+func (a *AppleMusic) result(name string, artwork string, results []itunesResult) (*Result, error) {
+	result := &Result{Name: name, ArtworkURL: artwork}
+	for _, r := range results {
+		if r.Kind != "song" {
+			continue
+		}
+		result.Items = append(result.Items, Item{
+			Title:      r.TrackName,
+			Artists:    []string{r.ArtistName},
+			Duration:   time.Duration(r.TrackTimeMs) * time.Millisecond,
+			ArtworkURL: r.artwork(),
+		})
+	}
+	if len(result.Items) == 0 {
+		return nil, fmt.Errorf("apple music returned no songs")
+	}
+	if result.Name == "" {
+		result.Name = result.Items[0].Title
+		result.ArtworkURL = result.Items[0].ArtworkURL
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package resolver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+var deezerPattern = regexp.MustCompile(`^https?://(?:www\.)?deezer\.com/(?:[a-z]{2}(?:-[a-z]{2})?/)?(track|album|playlist)/(\d+)`)
+
+// Deezer uses the public API, which needs no credentials.
+type Deezer struct {
+	client *http.Client
+	// APIURL is the base of the API, replaced in tests.
+	APIURL string
+	// MaxTracks stops paging through huge playlists.
+	MaxTracks int
+}
+
+func NewDeezer(client *http.Client) *Deezer {
+	return &Deezer{client: client, APIURL: "https://api.deezer.com", MaxTracks: 5000}
+}
+
+func (d *Deezer) Name() string {
+	return "Deezer"
+}
+
+func (d *Deezer) Match(link string) bool {
+	return deezerPattern.MatchString(link)
+}
+
+type deezerError struct {
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (e deezerError) err() error {
+	if e.Error == nil {
+		return nil
+	}
+	return fmt.Errorf("deezer: %s: %s", e.Error.Type, e.Error.Message)
+}
+
+type deezerTrack struct {
+	deezerError
+	Title    string `json:"title"`
+	ISRC     string `json:"isrc"`
+	Duration int    `json:"duration"`
+	Artist   struct {
+		Name string `json:"name"`
+	} `json:"artist"`
+	Contributors []struct {
+		Name string `json:"name"`
+	} `json:"contributors"`
+	Album struct {
+		CoverXL string `json:"cover_xl"`
+	} `json:"album"`
+}
+
+func (t deezerTrack) item(artwork string) Item {
+	item := Item{
+		Title:      t.Title,
+		ISRC:       t.ISRC,
+		Duration:   time.Duration(t.Duration) * time.Second,
+		ArtworkURL: cmp.Or(t.Album.CoverXL, artwork),
+	}
+	for _, contributor := range t.Contributors {
+		item.Artists = append(item.Artists, contributor.Name)
+	}
+	if len(item.Artists) == 0 && t.Artist.Name != "" {
+		item.Artists = []string{t.Artist.Name}
+	}
+	return item
+}
+
+type deezerCollection struct {
+	deezerError
+	Title     string `json:"title"`
+	CoverXL   string `json:"cover_xl"`
+	PictureXL string `json:"picture_xl"`
+}
+
+type deezerTrackPage struct {
+	deezerError
+	Data []deezerTrack `json:"data"`
+	Next string        `json:"next"`
+}
+
+func (d *Deezer) Resolve(ctx context.Context, link string) (*Result, error) {
+	match := deezerPattern.FindStringSubmatch(link)
+	if match == nil {
+		return nil, ErrUnsupported
+	}
+	kind, id := match[1], match[2]
+
+	if kind == "track" {
+		var track deezerTrack
+		if err := getJSON(ctx, d.client, fmt.Sprintf("%s/track/%s", d.APIURL, id), &track); err != nil {
+			return nil, err
+		}
+		if err := track.err(); err != nil {
+			return nil, err
+		}
+		item := track.item("")
+		return &Result{Name: track.Title, ArtworkURL: item.ArtworkURL, Items: []Item{item}}, nil
+	}
+
+	var collection deezerCollection
+	if err := getJSON(ctx, d.client, fmt.Sprintf("%s/%s/%s", d.APIURL, kind, id), &collection); err != nil {
+		return nil, err
+	}
+	if err := collection.err(); err != nil {
+		return nil, err
+	}
+	result := &Result{Name: collection.Title, ArtworkURL: cmp.Or(collection.CoverXL, collection.PictureXL)}
+
+	next := fmt.Sprintf("%s/%s/%s/tracks?limit=100", d.APIURL, kind, id)
+	for next != "" && len(result.Items) < d.MaxTracks {
+		var page deezerTrackPage
+		if err := getJSON(ctx, d.client, next, &page); err != nil {
+			return nil, err
+		}
+		if err := page.err(); err != nil {
+			return nil, err
+		}
+		for _, track := range page.Data {
+			result.Items = append(result.Items, track.item(result.ArtworkURL))
+		}
+		next = page.Next
+	}
+	return result, nil
+}
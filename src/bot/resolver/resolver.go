@@ -0,0 +1,97 @@
+// Package resolver turns links to streaming services that Lavalink cannot
+// play on its own into metadata that can be searched on a playable source.
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrUnsupported = errors.New("link type is not supported")
+
+// Item is a single track as described by the service it was linked from.
+type Item struct {
+	Title      string
+	Artists    []string
+	ISRC       string
+	Duration   time.Duration
+	ArtworkURL string
+}
+
+// Query returns a search query likely to find the item on another service.
+func (i Item) Query() string {
+	if len(i.Artists) == 0 {
+		return i.Title
+	}
+	return strings.Join(i.Artists, ", ") + " - " + i.Title
+}
+
+// Result is what a link resolved to: a single track, or every track of an
+// album or playlist.
+type Result struct {
+	Name       string
+	ArtworkURL string
+	Items      []Item
+}
+
+type Resolver interface {
+	Name() string
+	Match(link string) bool
+	Resolve(ctx context.Context, link string) (*Result, error)
+}
+
+// Resolvers is tried in order, the first one matching a link wins.
+type Resolvers []Resolver
+
+// Default returns the resolvers for every supported service.
+func Default(client *http.Client) Resolvers {
+	return Resolvers{
+		NewSpotify(client),
+		NewDeezer(client),
+		NewAppleMusic(client),
+	}
+}
+
+func (r Resolvers) Find(link string) Resolver {
+	for _, resolver := range r {
+		if resolver.Match(link) {
+			return resolver
+		}
+	}
+	return nil
+}
+
+// get performs a GET request and returns the body, failing on any status
+// other than 200.
+func get(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept-Language", "en")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	body, err := get(ctx, client, url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,264 @@
+package resolver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixtureServer serves recorded responses from testdata by request path and
+// query. "{{server}}" in a fixture is replaced by the server's URL, for
+// responses linking to further pages.
+func fixtureServer(t *testing.T, routes map[string]string) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path
+		if r.URL.RawQuery != "" {
+			key += "?" + r.URL.RawQuery
+		}
+		name, ok := routes[key]
+		if !ok {
+			t.Errorf("unexpected request %s", key)
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(strings.ReplaceAll(string(data), "{{server}}", server.URL)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func resolve(t *testing.T, r Resolver, link string) *Result {
+	t.Helper()
+	if !r.Match(link) {
+		t.Fatalf("%s does not match %s", r.Name(), link)
+	}
+	result, err := r.Resolve(context.Background(), link)
+	if err != nil {
+		t.Fatalf("resolving %s: %v", link, err)
+	}
+	return result
+}
+
+func checkItems(t *testing.T, got []Item, want []Item) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d items, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("item %d:\ngot  %+v\nwant %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSpotify(t *testing.T) {
+	server := fixtureServer(t, map[string]string{
+		"/track/0VjIjW4GlUZAMYd2vXMi3b":    "spotify_track.html",
+		"/album/4m2880jivSbbyEGAKfITCa":    "spotify_album.html",
+		"/playlist/37i9dQZF1DXcBWIGoYBM5M": "spotify_playlist.html",
+	})
+	spotify := NewSpotify(server.Client())
+	spotify.EmbedURL = server.URL
+
+	t.Run("track", func(t *testing.T) {
+		result := resolve(t, spotify, "https://open.spotify.com/intl-de/track/0VjIjW4GlUZAMYd2vXMi3b?si=abc")
+		artwork := "https://image-cdn-ak.spotifycdn.com/image/ab67616d0000b273"
+		if result.Name != "Blinding Lights" || result.ArtworkURL != artwork {
+			t.Errorf("got name %q and artwork %q", result.Name, result.ArtworkURL)
+		}
+		checkItems(t, result.Items, []Item{{
+			Title:      "Blinding Lights",
+			Artists:    []string{"The Weeknd"},
+			Duration:   200040 * time.Millisecond,
+			ArtworkURL: artwork,
+		}})
+	})
+
+	t.Run("album", func(t *testing.T) {
+		result := resolve(t, spotify, "https://open.spotify.com/album/4m2880jivSbbyEGAKfITCa")
+		artwork := "https://i.scdn.co/image/ab67616d0000b273"
+		if result.Name != "Random Access Memories" || result.ArtworkURL != artwork {
+			t.Errorf("got name %q and artwork %q", result.Name, result.ArtworkURL)
+		}
+		checkItems(t, result.Items, []Item{
+			{Title: "Give Life Back to Music", Artists: []string{"Daft Punk"}, Duration: 275386 * time.Millisecond, ArtworkURL: artwork},
+			{Title: "Get Lucky (feat. Pharrell Williams and Nile Rodgers)", Artists: []string{"Daft Punk", "Pharrell Williams", "Nile Rodgers"}, Duration: 369626 * time.Millisecond, ArtworkURL: artwork},
+		})
+	})
+
+	t.Run("playlist", func(t *testing.T) {
+		result := resolve(t, spotify, "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M")
+		if result.Name != "Today's Top Hits" {
+			t.Errorf("got name %q", result.Name)
+		}
+		if len(result.Items) != 3 {
+			t.Fatalf("got %d items, want 3", len(result.Items))
+		}
+		// The artists are separated by a non-breaking space
+		if got := result.Items[1].Artists; !reflect.DeepEqual(got, []string{"Lady Gaga", "Bruno Mars"}) {
+			t.Errorf("got artists %q", got)
+		}
+	})
+}
+
+func TestDeezer(t *testing.T) {
+	server := fixtureServer(t, map[string]string{
+		"/track/3135556":                       "deezer_track.json",
+		"/track/1":                             "deezer_error.json",
+		"/album/302127":                        "deezer_album.json",
+		"/album/302127/tracks?limit=100":       "deezer_album_tracks_1.json",
+		"/album/302127/tracks?limit=2&index=2": "deezer_album_tracks_2.json",
+		"/playlist/908622995":                  "deezer_playlist.json",
+		"/playlist/908622995/tracks?limit=100": "deezer_playlist_tracks.json",
+	})
+	deezer := NewDeezer(server.Client())
+	deezer.APIURL = server.URL
+
+	t.Run("track", func(t *testing.T) {
+		result := resolve(t, deezer, "https://www.deezer.com/fr/track/3135556")
+		checkItems(t, result.Items, []Item{{
+			Title:      "Harder, Better, Faster, Stronger",
+			Artists:    []string{"Daft Punk"},
+			ISRC:       "GBDUW0000059",
+			Duration:   224 * time.Second,
+			ArtworkURL: "https://e-cdns-images.dzcdn.net/images/cover/2e018122cb56986277102d2041a592c8/1000x1000-000000-80-0-0.jpg",
+		}})
+	})
+
+	t.Run("album over several pages", func(t *testing.T) {
+		result := resolve(t, deezer, "https://deezer.com/album/302127")
+		if result.Name != "Discovery" {
+			t.Errorf("got name %q", result.Name)
+		}
+		var titles []string
+		for _, item := range result.Items {
+			titles = append(titles, item.Title)
+			if item.ArtworkURL != result.ArtworkURL {
+				t.Errorf("%s has artwork %q, want the album's", item.Title, item.ArtworkURL)
+			}
+		}
+		if want := []string{"One More Time", "Aerodynamic", "Digital Love"}; !reflect.DeepEqual(titles, want) {
+			t.Errorf("got titles %q, want %q", titles, want)
+		}
+	})
+
+	t.Run("playlist", func(t *testing.T) {
+		result := resolve(t, deezer, "https://www.deezer.com/en/playlist/908622995")
+		if result.Name != "Electro Hits" || result.ArtworkURL != "https://e-cdns-images.dzcdn.net/images/playlist/1000x1000.jpg" {
+			t.Errorf("got name %q and artwork %q", result.Name, result.ArtworkURL)
+		}
+		checkItems(t, result.Items, []Item{
+			{Title: "Titanium (feat. Sia)", Artists: []string{"David Guetta"}, ISRC: "GBUM71107611", Duration: 245 * time.Second, ArtworkURL: "https://e-cdns-images.dzcdn.net/images/cover/titanium/1000x1000.jpg"},
+			{Title: "Wake Me Up", Artists: []string{"Avicii"}, ISRC: "SEUM71301326", Duration: 247 * time.Second, ArtworkURL: "https://e-cdns-images.dzcdn.net/images/playlist/1000x1000.jpg"},
+		})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := deezer.Resolve(context.Background(), "https://www.deezer.com/track/1")
+		if err == nil || !strings.Contains(err.Error(), "DataException") {
+			t.Errorf("got error %v, want the API's", err)
+		}
+	})
+}
+
+func TestAppleMusic(t *testing.T) {
+	server := fixtureServer(t, map[string]string{
+		"/lookup?country=us&id=1441164589":              "itunes_song.json",
+		"/lookup?country=us&entity=song&id=1441164426":  "itunes_album.json",
+		"/lookup?country=us&id=1538003843%2C1485802967": "itunes_playlist_songs.json",
+		"/us/playlist/summer-hits/pl.u-76oNlGdCvz1WbJ":  "applemusic_playlist.html",
+	})
+	apple := NewAppleMusic(server.Client())
+	apple.LookupURL = server.URL + "/lookup"
+	apple.PageURL = server.URL
+
+	comeTogether := Item{
+		Title:      "Come Together",
+		Artists:    []string{"The Beatles"},
+		Duration:   259947 * time.Millisecond,
+		ArtworkURL: "https://is1-ssl.mzstatic.com/image/thumb/Music/abbey/600x600bb.jpg",
+	}
+
+	t.Run("song", func(t *testing.T) {
+		result := resolve(t, apple, "https://music.apple.com/us/song/come-together/1441164589")
+		if result.Name != "Come Together" {
+			t.Errorf("got name %q", result.Name)
+		}
+		checkItems(t, result.Items, []Item{comeTogether})
+	})
+
+	t.Run("song in album link", func(t *testing.T) {
+		result := resolve(t, apple, "https://music.apple.com/us/album/come-together/1441164426?i=1441164589")
+		checkItems(t, result.Items, []Item{comeTogether})
+	})
+
+	t.Run("album", func(t *testing.T) {
+		result := resolve(t, apple, "https://music.apple.com/us/album/abbey-road-remastered/1441164426")
+		if result.Name != "Abbey Road (Remastered)" || result.ArtworkURL != comeTogether.ArtworkURL {
+			t.Errorf("got name %q and artwork %q", result.Name, result.ArtworkURL)
+		}
+		if len(result.Items) != 2 || result.Items[1].Title != "Something" {
+			t.Errorf("got items %+v", result.Items)
+		}
+	})
+
+	t.Run("playlist", func(t *testing.T) {
+		result := resolve(t, apple, "https://music.apple.com/us/playlist/summer-hits/pl.u-76oNlGdCvz1WbJ")
+		if result.Name != "Summer Hits" || result.ArtworkURL != "https://is1-ssl.mzstatic.com/image/thumb/Features/summer/1200x630cw.png" {
+			t.Errorf("got name %q and artwork %q", result.Name, result.ArtworkURL)
+		}
+		checkItems(t, result.Items, []Item{
+			{Title: "Levitating", Artists: []string{"Dua Lipa"}, Duration: 203064 * time.Millisecond, ArtworkURL: "https://is1-ssl.mzstatic.com/image/thumb/Music/levitating/600x600bb.jpg"},
+			{Title: "Watermelon Sugar", Artists: []string{"Harry Styles"}, Duration: 174000 * time.Millisecond, ArtworkURL: "https://is1-ssl.mzstatic.com/image/thumb/Music/watermelon/600x600bb.jpg"},
+		})
+	})
+}
+
+func TestFind(t *testing.T) {
+	resolvers := Default(http.DefaultClient)
+	tests := map[string]string{
+		"https://open.spotify.com/track/0VjIjW4GlUZAMYd2vXMi3b": "Spotify",
+		"https://www.deezer.com/us/album/302127":                "Deezer",
+		"https://music.apple.com/gb/playlist/pl.u-76oNlGdCvz1W": "Apple Music",
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ":           "",
+	}
+	for link, want := range tests {
+		got := ""
+		if r := resolvers.Find(link); r != nil {
+			got = r.Name()
+		}
+		if got != want {
+			t.Errorf("Find(%q) = %q, want %q", link, got, want)
+		}
+	}
+}
+
+func TestItemQuery(t *testing.T) {
+	tests := []struct {
+		item Item
+		want string
+	}{
+		{Item{Title: "Come Together", Artists: []string{"The Beatles"}}, "The Beatles - Come Together"},
+		{Item{Title: "Get Lucky", Artists: []string{"Daft Punk", "Pharrell Williams"}}, "Daft Punk, Pharrell Williams - Get Lucky"},
+		{Item{Title: "Untitled"}, "Untitled"},
+		// The ISRC is searched separately, never mixed into the query
+		{Item{Title: "Wake Me Up", Artists: []string{"Avicii"}, ISRC: "SEUM71301326"}, "Avicii - Wake Me Up"},
+	}
+	for _, test := range tests {
+		if got := test.item.Query(); got != test.want {
+			t.Errorf("%+v.Query() = %q, want %q", test.item, got, test.want)
+		}
+	}
+}
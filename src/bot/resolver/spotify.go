@@ -0,0 +1,156 @@
+package resolver
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var spotifyPattern = regexp.MustCompile(`^https?://open\.spotify\.com/(?:intl-[a-zA-Z-]+/)?(track|album|playlist)/([A-Za-z0-9]+)`)
+
+// Spotify reads the public embed player page, which needs no API
+// credentials. It does not expose ISRCs and lists at most 100 tracks of a
+// playlist.
+type Spotify struct {
+	client *http.Client
+	// EmbedURL is the base of the embed pages, replaced in tests.
+	EmbedURL string
+}
+
+func NewSpotify(client *http.Client) *Spotify {
+	return &Spotify{client: client, EmbedURL: "https://open.spotify.com/embed"}
+}
+
+func (s *Spotify) Name() string {
+	return "Spotify"
+}
+
+func (s *Spotify) Match(link string) bool {
+	return spotifyPattern.MatchString(link)
+}
+
+type spotifyImage struct {
+	URL   string `json:"url"`
+	Width int    `json:"width"`
+}
+
+type spotifyEntity struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Title    string `json:"title"`
+	Duration int64  `json:"duration"`
+	Artists  []struct {
+		Name string `json:"name"`
+	} `json:"artists"`
+	CoverArt struct {
+		Sources []spotifyImage `json:"sources"`
+	} `json:"coverArt"`
+	VisualIdentity struct {
+		Image []spotifyImage `json:"image"`
+	} `json:"visualIdentity"`
+	TrackList []struct {
+		Title    string `json:"title"`
+		Subtitle string `json:"subtitle"`
+		Duration int64  `json:"duration"`
+	} `json:"trackList"`
+}
+
+func (e spotifyEntity) artwork() string {
+	images := append(e.CoverArt.Sources, e.VisualIdentity.Image...)
+	best := spotifyImage{}
+	for _, image := range images {
+		if image.Width >= best.Width {
+			best = image
+		}
+	}
+	return best.URL
+}
+
+func (s *Spotify) Resolve(ctx context.Context, link string) (*Result, error) {
+	match := spotifyPattern.FindStringSubmatch(link)
+	if match == nil {
+		return nil, ErrUnsupported
+	}
+
+	body, err := get(ctx, s.client, fmt.Sprintf("%s/%s/%s", s.EmbedURL, match[1], match[2]))
+	if err != nil {
+		return nil, err
+	}
+	entity, err := parseSpotifyEmbed(body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Name: entity.Name, ArtworkURL: entity.artwork()}
+	if match[1] == "track" {
+		item := Item{
+			Title:      cmp.Or(entity.Title, entity.Name),
+			Duration:   time.Duration(entity.Duration) * time.Millisecond,
+			ArtworkURL: result.ArtworkURL,
+		}
+		for _, artist := range entity.Artists {
+			item.Artists = append(item.Artists, artist.Name)
+		}
+		result.Items = []Item{item}
+		return result, nil
+	}
+
+	for _, track := range entity.TrackList {
+		result.Items = append(result.Items, Item{
+			Title:      track.Title,
+			Artists:    splitArtists(track.Subtitle),
+			Duration:   time.Duration(track.Duration) * time.Millisecond,
+			ArtworkURL: result.ArtworkURL,
+		})
+	}
+	return result, nil
+}
+
+// parseSpotifyEmbed extracts the entity from the Next.js data embedded in
+// the page.
+func parseSpotifyEmbed(body []byte) (*spotifyEntity, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	script := doc.Find("script#__NEXT_DATA__").Text()
+	if script == "" {
+		return nil, fmt.Errorf("spotify embed page has no data")
+	}
+
+	var data struct {
+		Props struct {
+			PageProps struct {
+				State struct {
+					Data struct {
+						Entity spotifyEntity `json:"entity"`
+					} `json:"data"`
+				} `json:"state"`
+			} `json:"pageProps"`
+		} `json:"props"`
+	}
+	if err := json.Unmarshal([]byte(script), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse spotify embed data: %w", err)
+	}
+	return &data.Props.PageProps.State.Data.Entity, nil
+}
+
+// splitArtists splits the comma separated artist list of a track list entry.
+func splitArtists(subtitle string) []string {
+	subtitle = strings.ReplaceAll(subtitle, "\u00a0", " ")
+	var artists []string
+	for _, artist := range strings.Split(subtitle, ",") {
+		if artist = strings.TrimSpace(artist); artist != "" {
+			artists = append(artists, artist)
+		}
+	}
+	return artists
+}
@@ -1,7 +1,13 @@
 package bot
 
 import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/lavalink"
 )
 
 // Constants for consistent styling
@@ -25,6 +31,10 @@ const (
 	IconSuccess = "✅"
 	IconError   = "❌"
 	IconEmpty   = "🏜️"
+	IconWarning = "⚠️"
+	IconConfig  = "⚙️"
+	IconRadio   = "📻"
+	IconLive    = "🔴"
 
 	IconVolume = "🔊"
 	IconBass   = "🎚️"
@@ -99,3 +109,137 @@ func (b *Bot) SendComplexResponse(i *discordgo.Interaction, title string, descri
 
 	return err
 }
+
+// EditResponse replaces the card of an interaction that was already
+// responded to, e.g. to report progress.
+func (b *Bot) EditResponse(i *discordgo.Interaction, title string, description string, thumbURL string, color int) error {
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: description,
+		Color:       color,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Jukeboxitus Music",
+		},
+	}
+	if thumbURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
+			URL: thumbURL,
+		}
+	}
+
+	_, err := b.Session.InteractionResponseEdit(i, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+	return err
+}
+
+// UpdateResponse replaces the message a button or select menu belongs to,
+// removing its components.
+func (b *Bot) UpdateResponse(i *discordgo.Interaction, title string, description string, thumbURL string, color int) error {
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: description,
+		Color:       color,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Jukeboxitus Music",
+		},
+	}
+	if thumbURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{
+			URL: thumbURL,
+		}
+	}
+
+	return b.Session.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
+
+// interactionTokenTTL is how long an interaction can be answered or edited.
+const interactionTokenTTL = 15 * time.Minute
+
+// interactionContext bounds work done to answer an interaction by timeout,
+// and by the time its token expires since nothing can be sent after that.
+func interactionContext(i *discordgo.Interaction, timeout time.Duration) (context.Context, context.CancelFunc) {
+	deadline := time.Now().Add(timeout)
+	if created, err := discordgo.SnowflakeTimestamp(i.ID); err == nil {
+		deadline = minTime(deadline, created.Add(interactionTokenTTL))
+	}
+	return context.WithDeadline(context.Background(), deadline)
+}
+
+func minTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// trackLink renders a track as a markdown link, or just its title when the
+// source has no URI.
+func trackLink(track lavalink.Track) string {
+	info := trackInfo(track)
+	if info.URI == nil {
+		return fmt.Sprintf("`%s`", info.Title)
+	}
+	return fmt.Sprintf("[`%s`](<%s>)", info.Title, *info.URI)
+}
+
+func trackArtwork(track lavalink.Track) string {
+	if track.Info.ArtworkURL == nil {
+		return ""
+	}
+	return *track.Info.ArtworkURL
+}
+
+// pageButtons returns the first, previous, next and last page buttons of a
+// paged message. Their custom ID is "<prefix>:<button>:<page>".
+func pageButtons(prefix string, page int, pages int) []discordgo.MessageComponent {
+	button := func(name string, label string, target int, disabled bool) discordgo.Button {
+		return discordgo.Button{
+			CustomID: fmt.Sprintf("%s:%s:%d", prefix, name, target),
+			Label:    label,
+			Style:    discordgo.SecondaryButton,
+			Disabled: disabled,
+		}
+	}
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			button("first", "⏮", 0, page == 0),
+			button("prev", "◀", page-1, page == 0),
+			button("next", "▶", page+1, page == pages-1),
+			button("last", "⏭", pages-1, page == pages-1),
+		}},
+	}
+}
+
+// progressBar draws done out of total as a bar of width characters.
+func progressBar(done int, total int, width int) string {
+	filled := width
+	if total > 0 {
+		filled = min(done*width/total, width)
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// seekBar draws a playback position as a knob on a line of width segments.
+func seekBar(position lavalink.Duration, length lavalink.Duration, width int) string {
+	knob := 0
+	if length > 0 {
+		knob = min(max(int(int64(position)*int64(width)/int64(length)), 0), width-1)
+	}
+	return strings.Repeat("▬", knob) + "🔘" + strings.Repeat("▬", width-1-knob)
+}
+
+// truncate shortens s to at most max runes, marking the cut with an ellipsis.
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
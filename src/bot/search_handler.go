@@ -0,0 +1,180 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+)
+
+const (
+	searchResultLimit = 10
+	searchResultTTL   = 10 * time.Minute
+)
+
+// searchResults keeps the tracks offered by /search until someone picks one,
+// since a select menu value is too short to hold an encoded track.
+type searchResults struct {
+	mu      sync.Mutex
+	results map[string]searchResult
+}
+
+type searchResult struct {
+	tracks  []lavalink.Track
+	expires time.Time
+}
+
+func (r *searchResults) put(id string, tracks []lavalink.Track) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.results == nil {
+		r.results = make(map[string]searchResult)
+	}
+	now := time.Now()
+	for key, result := range r.results {
+		if now.After(result.expires) {
+			delete(r.results, key)
+		}
+	}
+	r.results[id] = searchResult{tracks: tracks, expires: now.Add(searchResultTTL)}
+}
+
+func (r *searchResults) get(id string) ([]lavalink.Track, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.results[id]
+	if !ok || time.Now().After(result.expires) {
+		return nil, false
+	}
+	return result.tracks, true
+}
+
+func (b *Bot) Search(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	options := optionMap(data.Options)
+	query := options["query"].StringValue()
+
+	sourceName := ""
+	if opt, ok := options["source"]; ok {
+		sourceName = opt.StringValue()
+	}
+	identifier, err := b.searchIdentifier(query, sourceName, b.Settings(event.GuildID))
+	if err != nil {
+		return b.SendResponse(event.Interaction, "Search Error",
+			fmt.Sprintf("%s Unknown source `%s`.", IconError, sourceName), ColorError)
+	}
+
+	if err := b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.Config().LoadTracksTimeout())
+	defer cancel()
+
+	result, err := b.Lavalink.BestNode().LoadTracks(ctx, identifier)
+	if err != nil {
+		return b.SendResponse(event.Interaction, "Search Error",
+			fmt.Sprintf("%s Error: `%s`", IconError, err), ColorError)
+	}
+
+	var tracks []lavalink.Track
+	switch data := result.Data.(type) {
+	case lavalink.Search:
+		tracks = data
+	case lavalink.Playlist:
+		tracks = data.Tracks
+	case lavalink.Track:
+		tracks = []lavalink.Track{data}
+	case lavalink.Exception:
+		return b.SendResponse(event.Interaction, "Search Error",
+			fmt.Sprintf("%s Error: `%s`", IconError, data), ColorError)
+	}
+	if len(tracks) == 0 {
+		return b.SendResponse(event.Interaction, "No Results",
+			fmt.Sprintf("%s Nothing found for: `%s`", IconEmpty, query), ColorDefault)
+	}
+	if len(tracks) > searchResultLimit {
+		tracks = tracks[:searchResultLimit]
+	}
+	b.searches.put(event.ID, tracks)
+
+	var sb strings.Builder
+	menuOptions := make([]discordgo.SelectMenuOption, 0, len(tracks))
+	for i, track := range tracks {
+		fmt.Fprintf(&sb, "**%d.** %s `%s`\n", i+1, track.Info.Title, formatLength(track))
+		menuOptions = append(menuOptions, discordgo.SelectMenuOption{
+			Label:       truncate(fmt.Sprintf("%d. %s", i+1, track.Info.Title), 100),
+			Description: truncate(track.Info.Author, 100),
+			Value:       strconv.Itoa(i),
+		})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s Search Results", IconSearch),
+		Description: sb.String(),
+		Color:       ColorDefault,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Jukeboxitus Music",
+		},
+	}
+	_, err = b.Session.InteractionResponseEdit(event.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+		Components: &[]discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    "search:" + event.ID,
+					Placeholder: "Pick a track to play",
+					Options:     menuOptions,
+				},
+			}},
+		},
+	})
+	return err
+}
+
+// SearchSelect plays or queues the track picked from a /search result.
+func (b *Bot) SearchSelect(event *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) error {
+	tracks, ok := b.searches.get(strings.TrimPrefix(data.CustomID, "search:"))
+	if !ok {
+		return b.UpdateResponse(event.Interaction, "Search Expired",
+			fmt.Sprintf("%s These results have expired, please search again.", IconEmpty), "", ColorWarning)
+	}
+	index, err := strconv.Atoi(data.Values[0])
+	if err != nil || index < 0 || index >= len(tracks) {
+		return fmt.Errorf("invalid search selection %q", data.Values[0])
+	}
+	track := requestedBy(tracks[index], event.Member.User.ID)
+
+	voiceState, err := b.Session.State.VoiceState(event.GuildID, event.Member.User.ID)
+	if err != nil {
+		return b.SendResponse(event.Interaction, "Connection Error",
+			fmt.Sprintf("%s You must be in a voice channel to play music!", IconError), ColorError)
+	}
+
+	settings := b.Settings(event.GuildID)
+	if limit := settings.MaxQueueLength; limit > 0 && b.Queues.Get(event.GuildID).Len() >= limit {
+		return b.sendQueueFull(event.Interaction, limit)
+	}
+
+	started, err := b.queueOrPlay(event.GuildID, voiceState.ChannelID, event.ChannelID, track)
+	if err != nil {
+		return b.UpdateResponse(event.Interaction, "Playback Error",
+			fmt.Sprintf("%s Error: `%s`", IconError, err), "", ColorError)
+	}
+
+	title, icon := "Track Added", IconPlay
+	if !started {
+		title = "Track Queued"
+		icon = IconQueue
+	}
+	return b.UpdateResponse(event.Interaction, title,
+		fmt.Sprintf("%s %s", icon, trackLink(track)), trackArtwork(track), ColorSuccess)
+}
@@ -2,18 +2,11 @@ package bot
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
-	"regexp"
-	"strings"
 
-	"github.com/PuerkitoBio/goquery"
 	"github.com/bwmarrin/discordgo"
 	"github.com/disgoorg/disgolink/v3/lavalink"
 	"github.com/disgoorg/snowflake/v2"
-	// lyrics "github.com/rhnvrm/lyric-api-go"
 )
 
 // Volume handles setting the player volume
@@ -107,162 +100,23 @@ func (b *Bot) EightD(event *discordgo.InteractionCreate, data discordgo.Applicat
 	return player.Update(context.Background(), lavalink.WithFilters(filters))
 }
 
-func (b *Bot) Lyrics(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
-	var artist, title string
-
-	// 1. Extract optional arguments from the interaction
-	argMap := make(map[string]string)
-	for _, opt := range data.Options {
-		argMap[opt.Name] = opt.StringValue()
-	}
-
-	artist = cleanLyricQuery(argMap["artist"])
-	title = cleanLyricQuery(argMap["title"])
-
-	// 2. Fallback: If both are empty, use the current player track
-	if artist == "" && title == "" {
-		player := b.Lavalink.ExistingPlayer(snowflake.MustParse(event.GuildID))
-		if player == nil || player.Track() == nil {
-			return b.SendResponse(event.Interaction, "Lyrics Error",
-				fmt.Sprintf("%s No song is currently playing and no search terms provided.", IconError), ColorError)
+// activeFilters names the filters a player has on, as set by /bass-boost and
+// /eight-d or by other clients of the node.
+func activeFilters(filters lavalink.Filters) []string {
+	var names []string
+	add := func(on bool, name string) {
+		if on {
+			names = append(names, name)
 		}
-
-		fullTitle := player.Track().Info.Title
-		parts := strings.Split(fullTitle, "-")
-		if len(parts) > 1 {
-			artist = cleanLyricQuery(parts[0])
-			title = cleanLyricQuery(parts[1])
-		} else {
-			title = cleanLyricQuery(fullTitle)
-		}
-	}
-
-	// 3. Defer response (Genius search + Scraping takes time)
-	b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
-	})
-
-	// 4. Fetch Lyrics using your Genius function
-	lyricText, err := b.getGeniusLyrics(artist, title)
-
-	// Fallback: If search failed with artist+title, try searching with just title
-	if (err != nil || lyricText == "") && artist != "" {
-		lyricText, err = b.getGeniusLyrics("", title)
-	}
-
-	if err != nil || lyricText == "" {
-		return b.SendResponse(event.Interaction, "Lyrics Not Found",
-			fmt.Sprintf("%s Could not find lyrics for **%s %s**", IconSearch, artist, title), ColorWarning)
-	}
-
-	// 5. Truncate for Discord limits (4096 characters)
-	if len(lyricText) > 4000 {
-		lyricText = lyricText[:3997] + "..."
-	}
-
-	// 6. Final response
-	displayHeader := fmt.Sprintf("Lyrics: %s", title)
-	if artist != "" {
-		displayHeader = fmt.Sprintf("Lyrics: %s - %s", artist, title)
-	}
-
-	return b.SendResponse(event.Interaction, displayHeader, lyricText, ColorDefault)
-}
-
-type GeniusResponse struct {
-	Response struct {
-		Hits []struct {
-			Result struct {
-				URL           string `json:"url"`
-				Title         string `json:"title"`
-				PrimaryArtist struct {
-					Name string `json:"name"`
-				} `json:"primary_artist"`
-			} `json:"result"`
-		} `json:"hits"`
-	} `json:"response"`
-}
-
-func (b *Bot) getGeniusLyrics(artist, title string) (string, error) {
-
-	// Merge artist and title for a clean search query
-	query := fmt.Sprintf("%s %s", artist, title)
-	searchURL := fmt.Sprintf("https://api.genius.com/search?q=%s", url.QueryEscape(query))
-
-	req, _ := http.NewRequest("GET", searchURL, nil)
-	req.Header.Set("Authorization", "Bearer "+b.GeniusToken)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
 	}
-	defer resp.Body.Close()
-
-	var gResp GeniusResponse
-	if err := json.NewDecoder(resp.Body).Decode(&gResp); err != nil {
-		return "", err
-	}
-
-	if len(gResp.Response.Hits) == 0 {
-		return "", fmt.Errorf("no results found for %s", query)
-	}
-
-	// Genius returns "hits" ordered by relevance. The first one is almost always correct.
-	songURL := gResp.Response.Hits[0].Result.URL
-
-	return scrapeLyrics(songURL)
-}
-
-func scrapeLyrics(geniusURL string) (string, error) {
-	// We need a User-Agent so Genius doesn't block the request
-	req, _ := http.NewRequest("GET", geniusURL, nil)
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("genius returned status %d", resp.StatusCode)
-	}
-
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return "", err
-	}
-
-	var lyricsBuilder strings.Builder
-
-	// Genius uses the 'data-lyrics-container' attribute for the actual lyric text
-	doc.Find("div[data-lyrics-container='true']").Each(func(i int, s *goquery.Selection) {
-		// Replace <br> tags with actual newlines
-		s.Find("br").ReplaceWithHtml("\n")
-
-		// Get the text and append it
-		lyricsBuilder.WriteString(s.Text() + "\n")
-	})
-
-	finalLyrics := strings.TrimSpace(lyricsBuilder.String())
-
-	if finalLyrics == "" {
-		return "", fmt.Errorf("could not parse lyrics from page")
-	}
-
-	return finalLyrics, nil
-}
-
-func cleanLyricQuery(input string) string {
-	// Remove common YouTube suffixes
-	re := regexp.MustCompile(`(?i)\(?(official|music|video|lyric|hd|audio|visualizer)\)?`)
-	input = re.ReplaceAllString(input, "")
-
-	// Remove brackets/parentheses and their contents if they are left over
-	re = regexp.MustCompile(`\[.*?\]|\(.*?\)|feat\..*`)
-	input = re.ReplaceAllString(input, "")
-
-	return strings.TrimSpace(input)
+	add(filters.Equalizer != nil, "Bass Boost")
+	add(filters.Rotation != nil, "8-D Audio")
+	add(filters.Timescale != nil, "Timescale")
+	add(filters.Tremolo != nil, "Tremolo")
+	add(filters.Vibrato != nil, "Vibrato")
+	add(filters.Karaoke != nil, "Karaoke")
+	add(filters.Distortion != nil, "Distortion")
+	add(filters.ChannelMix != nil, "Channel Mix")
+	add(filters.LowPass != nil, "Low Pass")
+	return names
 }
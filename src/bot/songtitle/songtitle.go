@@ -0,0 +1,208 @@
+// Package songtitle extracts the artist and song from the titles of uploaded
+// videos and tracks, which mix them with tags such as "(Official Video)".
+package songtitle
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Song is what a title says about the song it names.
+type Song struct {
+	Artist   string
+	Title    string
+	Featured []string
+	// Version is a tag such as "Live at River Plate" or "Remastered 2011".
+	Version string
+}
+
+var (
+	// brackets matches bracketed parts of a title, in the bracket styles of
+	// several languages.
+	brackets = regexp.MustCompile(`\([^()]*\)|\[[^\[\]]*\]|\{[^{}]*\}|（[^（）]*）|【[^【】]*】|〔[^〔〕]*〕|［[^［］]*］`)
+	// quotes matches quoted titles, as in "Artist「Song」" or
+	// "Artist 'Song' MV". Single quotes need two characters inside, so
+	// "Rock 'n' Roll" is not a quote.
+	quotes = regexp.MustCompile(`「([^「」]+)」|『([^『』]+)』|《([^《》]+)》|«([^«»]+)»|【([^【】]+)】|“([^“”]+)”|"([^"]+)"|(?:^|\s)'([^']{2,})'(?:\s|$)`)
+	// closingQuotes are followed by a space, so trailing tags after them
+	// are recognized.
+	closingQuotes = regexp.MustCompile(`([」』》»】”])(\S)`)
+
+	// separators split "Artist - Song", the spaces keep hyphenated names
+	// such as Jay-Z whole.
+	separators = regexp.MustCompile(`\s+[-–—~]\s+`)
+	// featuring introduces featured artists, in a title or an artist name.
+	featuring = regexp.MustCompile(`(?i)\s+(?:feat\.?|ft\.?|featuring)\s+`)
+	// collaborators separate the artists of a collaboration. Only a lower
+	// case x does, for names like "Lil Nas X".
+	collaborators = regexp.MustCompile(`\s+(?:x|×|(?i:feat\.?|ft\.?|featuring))\s+`)
+
+	// noise matches bracketed tags that say nothing about the song, such as
+	// "Official Video" or "MV".
+	noise = regexp.MustCompile(`(?i)^(?:official|officiel|oficial|ufficiale|offizielles?|video|vídeo|vidéo|clip|audio|lyrics?|letra|paroles|visuali[sz]er|m/?v|hd|hq|4k|1080p|full|explicit|clean|color coded|eng|sub|subs|歌詞|公式|music|musik|música|musique)(?:[\s/&+-]+(?:official|officiel|oficial|music|musical|musik|video|vídeo|vidéo|clip|audio|lyrics?|letra|paroles|visuali[sz]er|m/?v|hd|hq|4k|version|with|sub|subs|eng|歌詞|公式))*$`)
+	// version matches bracketed tags naming a version of the song.
+	version = regexp.MustCompile(`(?i)\b(?:remaster(?:ed)?|live|en vivo|ao vivo|acoustic|unplugged|remix|mix|edit|version|ver\.|demo|instrumental|cover|sped up|slowed|reverb|extended|radio)\b`)
+	// trailingNoise matches tags left after the title without brackets.
+	trailingNoise = regexp.MustCompile(`(?i)[\s|/-]+(?:official\s+(?:music\s+)?video|official\s+audio|official\s+mv|lyric\s+video|music\s+video|lyrics|audio|mv|hd|4k)\s*$`)
+
+	// artistList separates the names in "A, B & C".
+	artistList = regexp.MustCompile(`\s*(?:,|&|\band\b)\s*`)
+
+	topic = regexp.MustCompile(`(?i)\s+-\s+topic$`)
+	vevo  = regexp.MustCompile(`(?i)\s*vevo$`)
+)
+
+// Parse reads the artist, song, featured artists and version from a title.
+// author is the uploader or artist the source reported, used when the title
+// does not name the artist.
+func Parse(title string, author string) Song {
+	var song Song
+	text := strings.Join(strings.Fields(title), " ")
+
+	// Bracketed tags are sorted out first: noise is dropped, featured
+	// artists and versions are kept aside, anything else stays
+	text = brackets.ReplaceAllStringFunc(text, func(part string) string {
+		inner := strings.TrimSpace(trimBrackets(part))
+		switch {
+		case inner == "" || noise.MatchString(inner):
+			return " "
+		case featuring.MatchString(" " + inner):
+			song.Featured = append(song.Featured, splitArtists(featuring.Split(" "+inner, 2)[1])...)
+			return " "
+		case version.MatchString(inner):
+			if song.Version == "" {
+				song.Version = inner
+			}
+			return " "
+		}
+		return part
+	})
+	text = strings.Join(strings.Fields(text), " ")
+
+	// "Song | Channel" and similar keep the first part
+	if before, _, ok := strings.Cut(text, " | "); ok {
+		text = before
+	}
+	text = closingQuotes.ReplaceAllString(text, "$1 $2")
+	for trailingNoise.MatchString(text) {
+		stripped := strings.TrimSpace(trailingNoise.ReplaceAllString(text, ""))
+		if stripped == "" {
+			// The whole title is a word like "Audio"
+			break
+		}
+		text = stripped
+	}
+
+	artist, name := "", text
+	if match := quotes.FindStringSubmatchIndex(text); match != nil && !separators.MatchString(text) {
+		artist = strings.TrimSpace(text[:match[0]])
+		name = quoted(text, match)
+	} else if parts := separators.Split(text, 2); len(parts) == 2 {
+		artist, name = parts[0], parts[1]
+	} else if before, after, ok := cutBy(text, author); ok {
+		name, artist = before, after
+	}
+
+	// A dash tag after the song, as in "Song - Remastered 2009"
+	if parts := separators.Split(name, 2); len(parts) == 2 && version.MatchString(parts[1]) {
+		name = parts[0]
+		if song.Version == "" {
+			song.Version = strings.TrimSpace(parts[1])
+		}
+	}
+
+	// Featured artists named outside brackets
+	if parts := featuring.Split(name, 2); len(parts) == 2 {
+		name = parts[0]
+		song.Featured = append(song.Featured, splitArtists(parts[1])...)
+	}
+	if artist == "" {
+		artist = CleanAuthor(author)
+	}
+	// Names in another script, as in "BTS (방탄소년단)", are left out
+	artist = strings.Join(strings.Fields(brackets.ReplaceAllString(artist, " ")), " ")
+	artists := collaborators.Split(artist, -1)
+	song.Artist = strings.TrimSpace(artists[0])
+	for _, collaborator := range artists[1:] {
+		song.Featured = append(song.Featured, splitArtists(collaborator)...)
+	}
+	song.Title = strings.Trim(strings.TrimSpace(name), `"'“”`)
+	if song.Title == "" {
+		// Nothing but tags, as in "(Official Video)", is better searched
+		// as it is than not at all
+		song.Title = strings.Join(strings.Fields(title), " ")
+	}
+	return song
+}
+
+// CleanAuthor strips what sources add to an artist's channel name, such as
+// "Artist - Topic" or "ArtistVEVO".
+func CleanAuthor(author string) string {
+	author = topic.ReplaceAllString(strings.TrimSpace(author), "")
+	if vevo.MatchString(author) {
+		author = splitCamelCase(vevo.ReplaceAllString(author, ""))
+	}
+	return strings.TrimSpace(author)
+}
+
+// String renders the song as "Artist - Title", without the artist when it
+// is unknown.
+func (s Song) String() string {
+	if s.Artist == "" {
+		return s.Title
+	}
+	return s.Artist + " - " + s.Title
+}
+
+// cutBy splits "Song by Artist", but only when the artist is the author, since
+// "by" is common in song names.
+func cutBy(text string, author string) (string, string, bool) {
+	index := strings.LastIndex(strings.ToLower(text), " by ")
+	if index < 0 || author == "" {
+		return "", "", false
+	}
+	artist := strings.TrimSpace(text[index+len(" by "):])
+	if !strings.EqualFold(artist, CleanAuthor(author)) {
+		return "", "", false
+	}
+	return strings.TrimSpace(text[:index]), artist, true
+}
+
+func quoted(text string, match []int) string {
+	for i := 2; i < len(match); i += 2 {
+		if match[i] >= 0 {
+			return text[match[i]:match[i+1]]
+		}
+	}
+	return text
+}
+
+func trimBrackets(part string) string {
+	runes := []rune(part)
+	return string(runes[1 : len(runes)-1])
+}
+
+// splitArtists splits a list of artists such as "A, B & C".
+func splitArtists(list string) []string {
+	var artists []string
+	for _, artist := range artistList.Split(list, -1) {
+		if artist = strings.TrimSpace(artist); artist != "" {
+			artists = append(artists, artist)
+		}
+	}
+	return artists
+}
+
+// splitCamelCase puts spaces back into names like "TaylorSwift".
+func splitCamelCase(s string) string {
+	var sb strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			sb.WriteRune(' ')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package songtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		title  string
+		author string
+		want   Song
+	}{
+		// Separators
+		{"AC/DC - T.N.T. (Live at River Plate)", "AC/DC", Song{Artist: "AC/DC", Title: "T.N.T.", Version: "Live at River Plate"}},
+		{"Jay-Z - Empire State of Mind", "", Song{Artist: "Jay-Z", Title: "Empire State of Mind"}},
+		{"Daft Punk – One More Time", "", Song{Artist: "Daft Punk", Title: "One More Time"}},
+		{"Never Gonna Give You Up", "Rick Astley", Song{Artist: "Rick Astley", Title: "Never Gonna Give You Up"}},
+
+		// "Title by Artist" only when the artist is the author
+		{"Shape of You by Ed Sheeran", "Ed Sheeran", Song{Artist: "Ed Sheeran", Title: "Shape of You"}},
+		{"Stand by Me", "Ben E. King", Song{Artist: "Ben E. King", Title: "Stand by Me"}},
+		{"Killed by Death", "Motörhead", Song{Artist: "Motörhead", Title: "Killed by Death"}},
+
+		// Featured artists and collaborations
+		{"Calvin Harris - This Is What You Came For (feat. Rihanna)", "", Song{Artist: "Calvin Harris", Title: "This Is What You Came For", Featured: []string{"Rihanna"}}},
+		{"Mark Ronson - Uptown Funk ft. Bruno Mars", "", Song{Artist: "Mark Ronson", Title: "Uptown Funk", Featured: []string{"Bruno Mars"}}},
+		{"Daft Punk - Get Lucky (Official Audio) ft. Pharrell Williams, Nile Rodgers", "", Song{Artist: "Daft Punk", Title: "Get Lucky", Featured: []string{"Pharrell Williams", "Nile Rodgers"}}},
+		{"Lil Nas X x Jack Harlow - Industry Baby", "", Song{Artist: "Lil Nas X", Title: "Industry Baby", Featured: []string{"Jack Harlow"}}},
+		{"Post Malone feat. Swae Lee - Sunflower", "", Song{Artist: "Post Malone", Title: "Sunflower", Featured: []string{"Swae Lee"}}},
+		{"Pitbull - Timber [ft. Ke$ha & Someone Else]", "", Song{Artist: "Pitbull", Title: "Timber", Featured: []string{"Ke$ha", "Someone Else"}}},
+
+		// Channel names
+		{"Bohemian Rhapsody (Remastered 2011)", "Queen - Topic", Song{Artist: "Queen", Title: "Bohemian Rhapsody", Version: "Remastered 2011"}},
+		{"Shake It Off", "TaylorSwiftVEVO", Song{Artist: "Taylor Swift", Title: "Shake It Off"}},
+		{"Rick Astley - Never Gonna Give You Up (Official Music Video)", "RickAstleyVEVO", Song{Artist: "Rick Astley", Title: "Never Gonna Give You Up"}},
+
+		// Noise and version tags
+		{"Queen - Bohemian Rhapsody (Official Video Remastered)", "", Song{Artist: "Queen", Title: "Bohemian Rhapsody", Version: "Official Video Remastered"}},
+		{"The Beatles - Here Comes the Sun - Remastered 2009", "", Song{Artist: "The Beatles", Title: "Here Comes the Sun", Version: "Remastered 2009"}},
+		{"Nirvana - Come As You Are (MTV Unplugged)", "", Song{Artist: "Nirvana", Title: "Come As You Are", Version: "MTV Unplugged"}},
+		{"Coldplay - Yellow [HD] [Lyrics]", "", Song{Artist: "Coldplay", Title: "Yellow"}},
+		{"Adele - Hello Official Music Video", "", Song{Artist: "Adele", Title: "Hello"}},
+		{"Billie Eilish - bad guy | Vevo Live Performance", "", Song{Artist: "Billie Eilish", Title: "bad guy"}},
+		{"Elvis Presley - Rock 'n' Roll Music", "", Song{Artist: "Elvis Presley", Title: "Rock 'n' Roll Music"}},
+
+		// CJK and fullwidth brackets
+		{"YOASOBI「夜に駆ける」Official Music Video", "Ayase / YOASOBI", Song{Artist: "YOASOBI", Title: "夜に駆ける"}},
+		{"米津玄師 MV「Lemon」", "", Song{Artist: "米津玄師 MV", Title: "Lemon"}},
+		{"周杰倫【告白氣球】Official MV", "", Song{Artist: "周杰倫", Title: "告白氣球"}},
+		{"IU - Blueming（Official MV）", "", Song{Artist: "IU", Title: "Blueming"}},
+		{"BTS (방탄소년단) 'Dynamite' Official MV", "HYBE LABELS", Song{Artist: "BTS", Title: "Dynamite"}},
+		{"LiSA『紅蓮華』", "", Song{Artist: "LiSA", Title: "紅蓮華"}},
+
+		// Edge inputs keep a title to search for
+		{"(Official Video)", "", Song{Title: "(Official Video)"}},
+		{"(Official Video)", "Rick Astley", Song{Artist: "Rick Astley", Title: "(Official Video)"}},
+		{"Audio", "", Song{Title: "Audio"}},
+		{"  Some   Song  ", "", Song{Title: "Some Song"}},
+		{"", "", Song{}},
+	}
+	for _, test := range tests {
+		if got := Parse(test.title, test.author); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Parse(%q, %q)\ngot  %+v\nwant %+v", test.title, test.author, got, test.want)
+		}
+	}
+}
+
+func TestCleanAuthor(t *testing.T) {
+	tests := map[string]string{
+		"Queen - Topic":     "Queen",
+		"TaylorSwiftVEVO":   "Taylor Swift",
+		"AdeleVEVO":         "Adele",
+		" Rick Astley ":     "Rick Astley",
+		"Vevo":              "",
+		"Twenty One Pilots": "Twenty One Pilots",
+	}
+	for author, want := range tests {
+		if got := CleanAuthor(author); got != want {
+			t.Errorf("CleanAuthor(%q) = %q, want %q", author, got, want)
+		}
+	}
+}
+
+func TestSongString(t *testing.T) {
+	if got := (Song{Artist: "Queen", Title: "Bohemian Rhapsody"}).String(); got != "Queen - Bohemian Rhapsody" {
+		t.Errorf("got %q", got)
+	}
+	if got := (Song{Title: "Bohemian Rhapsody"}).String(); got != "Bohemian Rhapsody" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/log"
+
+	bot_config "jukeboxitus/src/bot/config"
+)
+
+// searchSource is something /play and /search can look a query up on.
+type searchSource struct {
+	Name   string
+	Prefix lavalink.SearchType
+}
+
+const nodeInfoTTL = 5 * time.Minute
+
+var errNoNode = errors.New("no lavalink node available")
+
+// nodeInfoCache avoids asking the node for its info on every autocomplete.
+type nodeInfoCache struct {
+	mu      sync.Mutex
+	info    *lavalink.Info
+	fetched time.Time
+}
+
+// invalidate forgets the cached info, e.g. after the node list changed.
+func (c *nodeInfoCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.info = nil
+}
+
+func (b *Bot) nodeInfo(ctx context.Context) (*lavalink.Info, error) {
+	b.infoCache.mu.Lock()
+	defer b.infoCache.mu.Unlock()
+
+	if b.infoCache.info != nil && time.Since(b.infoCache.fetched) < nodeInfoTTL {
+		return b.infoCache.info, nil
+	}
+
+	node := b.Lavalink.BestNode()
+	if node == nil {
+		return nil, errNoNode
+	}
+	info, err := node.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b.infoCache.info = info
+	b.infoCache.fetched = time.Now()
+	return info, nil
+}
+
+// searchSources lists the built-in search types followed by the plugin ones
+// the node advertises.
+func (b *Bot) searchSources(ctx context.Context) []searchSource {
+	var managers []string
+	if info, err := b.nodeInfo(ctx); err != nil {
+		log.Error("failed to get node info: ", err)
+	} else {
+		managers = info.SourceManagers
+	}
+
+	var sources []searchSource
+	for _, searchType := range bot_config.SearchTypes() {
+		if searchType.IsPlugin() && !slices.Contains(managers, searchType.SourceManager()) {
+			continue
+		}
+		sources = append(sources, searchSource{searchType.String(), searchTypePrefix(searchType)})
+	}
+	return sources
+}
+
+// CheckSearchSources warns about search types in use, in the config or in any
+// guild's settings, that the node does not provide.
+func (b *Bot) CheckSearchSources(ctx context.Context) {
+	b.infoCache.invalidate()
+	info, err := b.nodeInfo(ctx)
+	if err != nil {
+		log.Error("failed to get node info: ", err)
+		return
+	}
+
+	check := func(searchType bot_config.SearchType, where string) {
+		if !slices.Contains(info.SourceManagers, searchType.SourceManager()) {
+			log.Warnf("search type %q used by %s is not available on the node (source managers: %s)",
+				searchType, where, strings.Join(info.SourceManagers, ", "))
+		}
+	}
+
+	check(b.Config().SearchType(), "the config")
+	for guildID, settings := range b.GuildSettings.All() {
+		if settings.SearchType != nil {
+			check(bot_config.ParseSearchType(*settings.SearchType), "guild "+guildID)
+		}
+	}
+}
+
+// lookupSource finds the search source chosen in a command option.
+func (b *Bot) lookupSource(ctx context.Context, name string) (searchSource, bool) {
+	for _, source := range b.searchSources(ctx) {
+		if strings.EqualFold(source.Name, name) {
+			return source, true
+		}
+	}
+	return searchSource{}, false
+}
+
+// searchIdentifier prepares a query for Lavalink. Links and queries that
+// already carry a "xxsearch:" prefix are passed through, everything else is
+// searched on the chosen source or else on the guild's default one.
+func (b *Bot) searchIdentifier(query string, sourceName string, settings Settings) (string, error) {
+	if urlPattern.MatchString(query) || searchPattern.MatchString(query) {
+		return query, nil
+	}
+	if sourceName == "" {
+		return applySearchType(settings.SearchType, query), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	source, ok := b.lookupSource(ctx, sourceName)
+	if !ok {
+		return "", fmt.Errorf("unknown source %q", sourceName)
+	}
+	return source.Prefix.Apply(query), nil
+}
+
+// SourceAutocomplete suggests the sources matching what was typed so far in
+// the focused "source" option.
+func (b *Bot) SourceAutocomplete(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	typed := ""
+	for _, opt := range data.Options {
+		if opt.Focused {
+			typed = strings.ToLower(opt.StringValue())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, source := range b.searchSources(ctx) {
+		if !strings.Contains(strings.ToLower(source.Name), typed) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  source.Name,
+			Value: source.Name,
+		})
+	}
+	if len(choices) > 25 {
+		choices = choices[:25]
+	}
+
+	return b.Session.InteractionRespond(event.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/disgoorg/disgolink/v3/lavalink"
+
+	"jukeboxitus/src/bot/songtitle"
+)
+
+// statsLimit is how many entries a /stats ranking shows.
+const statsLimit = 10
+
+// statsRanges are the time ranges /stats can look back over.
+var statsRanges = map[string]struct {
+	label  string
+	period time.Duration
+}{
+	"day":   {"in the last 24 hours", 24 * time.Hour},
+	"week":  {"in the last 7 days", 7 * 24 * time.Hour},
+	"month": {"in the last 30 days", 30 * 24 * time.Hour},
+	"year":  {"in the last 365 days", 365 * 24 * time.Hour},
+	"all":   {"of all time", 0},
+}
+
+// playCount is how often something was played and for how long.
+type playCount struct {
+	label    string
+	plays    int
+	listened lavalink.Duration
+}
+
+func (b *Bot) Stats(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error {
+	subcommand := data.Options[0]
+	options := optionMap(subcommand.Options)
+
+	statsRange := statsRanges["all"]
+	if opt, ok := options["range"]; ok {
+		if r, ok := statsRanges[opt.StringValue()]; ok {
+			statsRange = r
+		}
+	}
+	var since time.Time
+	if statsRange.period > 0 {
+		since = time.Now().Add(-statsRange.period)
+	}
+	plays := countedPlays(b.History.Plays(event.GuildID, since))
+
+	var (
+		title   string
+		ranking []playCount
+	)
+	switch subcommand.Name {
+	case "top-tracks":
+		title, ranking = "Top Tracks", rankPlays(plays, playTrack)
+	case "top-artists":
+		title, ranking = "Top Artists", rankPlays(plays, playArtist)
+	case "top-requesters":
+		title, ranking = "Top Requesters", rankPlays(plays, playRequester)
+	case "me":
+		return b.myStats(event, plays, statsRange.label)
+	}
+
+	if len(ranking) == 0 {
+		return b.SendResponse(event.Interaction, title,
+			fmt.Sprintf("%s Nothing was played %s.", IconEmpty, statsRange.label), ColorDefault)
+	}
+	return b.SendResponse(event.Interaction, fmt.Sprintf("%s %s", IconQueue, title),
+		fmt.Sprintf("*Most played %s*\n\n%s", statsRange.label, formatRanking(ranking, statsLimit)), ColorDefault)
+}
+
+// myStats shows what the user requested in the guild.
+func (b *Bot) myStats(event *discordgo.InteractionCreate, plays []Play, label string) error {
+	userID := event.Member.User.ID
+	plays = slices.DeleteFunc(plays, func(play Play) bool {
+		return play.RequesterID != userID
+	})
+	if len(plays) == 0 {
+		return b.SendResponse(event.Interaction, "Your Stats",
+			fmt.Sprintf("%s You didn't request anything %s.", IconEmpty, label), ColorDefault)
+	}
+
+	var listened lavalink.Duration
+	for _, play := range plays {
+		listened += play.Listened
+	}
+	description := fmt.Sprintf("*Your requests %s*\n\n%s **%d** tracks, **%s** of listening\n\n**Top tracks**\n%s\n**Top artists**\n%s",
+		label, IconPlay, len(plays), formatTotal(listened),
+		formatRanking(rankPlays(plays, playTrack), 5), formatRanking(rankPlays(plays, playArtist), 5))
+	return b.SendResponse(event.Interaction, fmt.Sprintf("%s Your Stats", IconQueue), description, ColorDefault)
+}
+
+// countedPlays leaves out tracks that failed to load.
+func countedPlays(plays []Play) []Play {
+	return slices.DeleteFunc(plays, func(play Play) bool {
+		return play.Reason == PlayLoadFailed
+	})
+}
+
+// rankPlays groups plays by the key and label of by, most played first. Plays
+// without a key are left out.
+func rankPlays(plays []Play, by func(Play) (string, string)) []playCount {
+	counts := make(map[string]*playCount)
+	var order []*playCount
+	for _, play := range plays {
+		key, label := by(play)
+		if key == "" {
+			continue
+		}
+		count, ok := counts[key]
+		if !ok {
+			count = &playCount{label: label}
+			counts[key] = count
+			order = append(order, count)
+		}
+		count.plays++
+		count.listened += play.Listened
+	}
+
+	ranking := make([]playCount, 0, len(order))
+	for _, count := range order {
+		ranking = append(ranking, *count)
+	}
+	slices.SortStableFunc(ranking, func(a, b playCount) int {
+		return cmp.Or(cmp.Compare(b.plays, a.plays), cmp.Compare(b.listened, a.listened))
+	})
+	return ranking
+}
+
+func playTrack(play Play) (string, string) {
+	label := fmt.Sprintf("**%s**", play.Title)
+	if play.URI != "" {
+		label = fmt.Sprintf("**[%s](<%s>)**", play.Title, play.URI)
+	}
+	if play.Author != "" {
+		label += " by " + play.Author
+	}
+	return play.Identifier, label
+}
+
+// playArtist reads the artist from the track title, the uploader is often a
+// label or channel.
+func playArtist(play Play) (string, string) {
+	artist := songtitle.Parse(play.Title, play.Author).Artist
+	return strings.ToLower(artist), artist
+}
+
+// playRequester leaves out tracks nobody asked for, like autoplay.
+func playRequester(play Play) (string, string) {
+	if play.RequesterID == "" {
+		return "", ""
+	}
+	return play.RequesterID, "<@" + play.RequesterID + ">"
+}
+
+func formatRanking(ranking []playCount, limit int) string {
+	var sb strings.Builder
+	for i, count := range ranking[:min(len(ranking), limit)] {
+		plays := "plays"
+		if count.plays == 1 {
+			plays = "play"
+		}
+		fmt.Fprintf(&sb, "**%d.** %s • %d %s • `%s`\n", i+1, count.label, count.plays, plays, formatTotal(count.listened))
+	}
+	return sb.String()
+}
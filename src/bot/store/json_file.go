@@ -0,0 +1,62 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JSONFile persists a single value as an indented JSON document. Saves go
+// through a temporary file and a rename, so a crash never leaves a half
+// written file behind.
+type JSONFile[T any] struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewJSONFile[T any](dir string, name string) *JSONFile[T] {
+	return &JSONFile[T]{path: filepath.Join(dir, name)}
+}
+
+func (f *JSONFile[T]) Path() string {
+	return f.path
+}
+
+// Load reads the stored value. A missing file yields the zero value.
+func (f *JSONFile[T]) Load() (T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var value T
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return value, nil
+	}
+	if err != nil {
+		return value, err
+	}
+
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
+
+func (f *JSONFile[T]) Save(value T) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
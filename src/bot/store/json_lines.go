@@ -0,0 +1,106 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// maxLineSize is the longest line JSONLines reads.
+const maxLineSize = 1 << 20
+
+// JSONLines persists a growing list of values as one JSON document per line.
+// Values are appended, so recording one never rewrites the others.
+type JSONLines[T any] struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewJSONLines[T any](dir string, name string) *JSONLines[T] {
+	return &JSONLines[T]{path: filepath.Join(dir, name)}
+}
+
+func (f *JSONLines[T]) Path() string {
+	return f.path
+}
+
+// Load reads the stored values. A missing file yields none. Lines that can't
+// be read, such as one cut short by a crash, are skipped and reported in the
+// error along with the values that could be read.
+func (f *JSONLines[T]) Load() ([]T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var (
+		values  []T
+		skipped int
+	)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxLineSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var value T
+		if err := json.Unmarshal(scanner.Bytes(), &value); err != nil {
+			skipped++
+			continue
+		}
+		values = append(values, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return values, err
+	}
+	if skipped > 0 {
+		return values, fmt.Errorf("skipped %d unreadable lines of %s", skipped, f.path)
+	}
+	return values, nil
+}
+
+// Append adds values to the end of the file, creating it if needed.
+func (f *JSONLines[T]) Append(values ...T) error {
+	var data []byte
+	for _, value := range values {
+		line, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	// A line cut short by a crash is ended, so it doesn't spoil the new ones
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			data = append([]byte{'\n'}, data...)
+		}
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"github.com/disgoorg/disgolink/v3/lavalink"
+	"github.com/disgoorg/log"
+)
+
+// TrackData is kept in a track's user data. Lavalink sends it back in player
+// events, so it follows the track through the queue and the player.
+type TrackData struct {
+	// Identifiers are tried in order to load a placeholder track, see
+	// newPlaceholder.
+	Identifiers []string `json:"identifiers,omitempty"`
+	// Title and Author replace what the source reported, e.g. for uploaded
+	// files that have no metadata.
+	Title  string `json:"title,omitempty"`
+	Author string `json:"author,omitempty"`
+	// StartPosition resumes an interrupted track where it stopped.
+	StartPosition lavalink.Duration `json:"startPosition,omitempty"`
+	// RequesterID is the user who queued the track.
+	RequesterID string `json:"requesterId,omitempty"`
+	// Batch is the interaction that queued a placeholder, whose response
+	// shows how many of its placeholders were loaded.
+	Batch string `json:"batch,omitempty"`
+}
+
+func trackData(track lavalink.Track) TrackData {
+	var data TrackData
+	if len(track.UserData) == 0 {
+		return data
+	}
+	if err := track.UserData.Unmarshal(&data); err != nil {
+		log.Error("invalid track user data: ", err)
+	}
+	return data
+}
+
+func withTrackData(track lavalink.Track, data TrackData) lavalink.Track {
+	withData, err := track.WithUserData(data)
+	if err != nil {
+		log.Error("failed to set track user data: ", err)
+		return track
+	}
+	return withData
+}
+
+// trackInfo returns the info of track with the title and author it was
+// queued with.
+func trackInfo(track lavalink.Track) lavalink.TrackInfo {
+	info := track.Info
+	data := trackData(track)
+	if data.Title != "" {
+		info.Title = data.Title
+	}
+	if data.Author != "" {
+		info.Author = data.Author
+	}
+	return info
+}
+
+// requestedBy records the user who queued track.
+func requestedBy(track lavalink.Track, userID string) lavalink.Track {
+	data := trackData(track)
+	data.RequesterID = userID
+	return withTrackData(track, data)
+}
+
+// newPlaceholder creates a lightweight track that only carries what the queue
+// displays. It is loaded from identifiers right before it plays.
+func newPlaceholder(info lavalink.TrackInfo, identifiers ...string) lavalink.Track {
+	return withTrackData(lavalink.Track{Info: info}, TrackData{Identifiers: identifiers})
+}
+
+// isPlaceholder reports whether track still has to be loaded before it can
+// be played.
+func isPlaceholder(track lavalink.Track) bool {
+	return track.Encoded == ""
+}
+
+// resumeAt makes track start at position the next time it plays. Streams
+// cannot seek and always start live.
+func resumeAt(track lavalink.Track, position lavalink.Duration) lavalink.Track {
+	if track.Info.IsStream {
+		return track
+	}
+	data := trackData(track)
+	data.StartPosition = position
+	return withTrackData(track, data)
+}
+
+// playOpts returns the player update that plays track, from its start
+// position if it has one.
+func playOpts(track lavalink.Track) []lavalink.PlayerUpdateOpt {
+	data := trackData(track)
+	if data.StartPosition <= 0 {
+		return []lavalink.PlayerUpdateOpt{lavalink.WithTrack(track)}
+	}
+	position := data.StartPosition
+	data.StartPosition = 0
+	return []lavalink.PlayerUpdateOpt{lavalink.WithTrack(withTrackData(track, data)), lavalink.WithPosition(position)}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	bot_config "jukeboxitus/src/bot/config"
+)
+
+const configUsage = "usage: jukeboxitus config check"
+
+// runConfigCommand implements the "config" subcommand and returns the exit code.
+func runConfigCommand(args []string) int {
+	if len(args) != 1 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	config, sources, err := bot_config.Load(readEmbeddedConfig(), configPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		return 1
+	}
+
+	fmt.Printf("Config file: %s\n", configPath())
+	config.WriteSummary(os.Stdout, sources)
+	fmt.Println()
+
+	problems := config.Validate()
+	if config.Lavalink.Hostname != "" && config.Lavalink.Port > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		version, err := checkNode(ctx, config.Lavalink)
+		if err != nil {
+			problems = append(problems, bot_config.Problem{
+				Key:      "Lavalink.Hostname",
+				Severity: bot_config.SeverityError,
+				Message:  err.Error(),
+			})
+		} else {
+			fmt.Printf("Lavalink node reachable, version %s\n", version)
+			problems = append(problems, checkSearchType(ctx, config)...)
+		}
+	}
+
+	errors := 0
+	for _, problem := range problems {
+		fmt.Printf("%s (%s)\n", problem, sources.Of(problem.Key))
+		if problem.Severity == bot_config.SeverityError {
+			errors++
+		}
+	}
+	if errors > 0 {
+		fmt.Printf("%d error(s) found\n", errors)
+		return 1
+	}
+
+	fmt.Println("Config OK")
+	return 0
+}
+
+// checkSearchType warns when the configured search type needs a source
+// manager that the node does not report.
+func checkSearchType(ctx context.Context, config bot_config.Config) []bot_config.Problem {
+	var info struct {
+		SourceManagers []string `json:"sourceManagers"`
+	}
+	if err := nodeRequest(ctx, config.Lavalink, "/v4/info", &info); err != nil {
+		return []bot_config.Problem{{
+			Key:      "Lavalink.Hostname",
+			Severity: bot_config.SeverityWarning,
+			Message:  fmt.Sprintf("could not read node info: %s", err),
+		}}
+	}
+
+	searchType := config.SearchType()
+	if slices.Contains(info.SourceManagers, searchType.SourceManager()) {
+		return nil
+	}
+	return []bot_config.Problem{{
+		Key:      "Lavalink.SearchType",
+		Severity: bot_config.SeverityWarning,
+		Message: fmt.Sprintf("search type %q is not available on the node (source managers: %s)",
+			searchType, strings.Join(info.SourceManagers, ", ")),
+	}}
+}
+
+// checkNode queries the version endpoint of a Lavalink node, which also
+// verifies the password.
+func checkNode(ctx context.Context, config bot_config.LavalinkConfig) (string, error) {
+	var version []byte
+	err := nodeRequest(ctx, config, "/version", &version)
+	return string(version), err
+}
+
+// nodeRequest performs an authenticated GET on the node. The body is decoded
+// as JSON into v, unless v is a *[]byte which receives the raw body.
+func nodeRequest(ctx context.Context, config bot_config.LavalinkConfig, path string, v any) error {
+	scheme := "http"
+	if config.Secured {
+		scheme = "https"
+	}
+	url := fmt.Sprintf("%s://%s:%d%s", scheme, config.Hostname, config.Port, path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", config.Password)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("node unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("node rejected the password (status %d)", resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("node returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if raw, ok := v.(*[]byte); ok {
+		*raw = body
+		return nil
+	}
+	return json.Unmarshal(body, v)
+}
@@ -2,16 +2,14 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/disgoorg/snowflake/v2"
-	"gopkg.in/yaml.v3"
 
 	"github.com/disgoorg/log"
 
@@ -19,10 +17,16 @@ import (
 
 	"jukeboxitus/src/bot"
 	bot_config "jukeboxitus/src/bot/config"
+	"jukeboxitus/src/bot/lyrics"
+	"jukeboxitus/src/bot/resolver"
 	"jukeboxitus/src/build"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
+	}
+
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	log.SetLevel(log.LevelInfo)
 	log.Info("starting discordgo example...")
@@ -31,63 +35,58 @@ func main() {
 
 	log.Info(build.BuildType())
 
-	config := loadConfig(build.ConfigFile)
-
-	token, tokenFromEnv := getEnv("TOKEN", config.Token)
-	if token == "" {
-		log.Fatal("missing 'TOKEN'")
+	config, sources, err := bot_config.Load(readEmbeddedConfig(), configPath())
+	if err != nil {
+		log.Fatal(err)
 		return
 	}
-	geniusToken, geniusTokenFromEnv := getEnv("GENIUS_TOKEN", config.GeniusToken)
-	if token == "" {
-		log.Fatal("missing 'TOKEN'")
-		return
+	for _, problem := range config.Validate() {
+		if problem.Severity == bot_config.SeverityError {
+			log.Fatal(problem)
+			return
+		}
+		log.Warn(problem)
 	}
-	name, nameFromEnv := getEnv("NAME", config.Lavalink.Name)
-	hostName, hostNameFromEnv := getEnv("HOSTNAME", config.Lavalink.Hostname)
-	if hostName == "" {
-		log.Fatal("missing 'HOSTNAME'")
+
+	config.WriteSummary(os.Stdout, sources)
+
+	guildSettings, err := bot.NewGuildSettingsManager(config.DataPath())
+	if err != nil {
+		log.Fatal("failed to load guild settings: ", err)
 		return
 	}
-	portStr, portFromEnv := getEnv("PORT", strconv.Itoa(config.Lavalink.Port))
-	if portStr == "" {
-		log.Fatal("missing 'PORT'")
+
+	playlists, err := bot.NewPlaylistStore(config.DataPath())
+	if err != nil {
+		log.Fatal("failed to load playlists: ", err)
 		return
 	}
-	searchTypeStr, searchTypeFromEnv := getEnv("SEARCH_TYPE", config.Lavalink.SearchType)
-	port, err := strconv.Atoi(portStr)
+
+	lyricsCache, err := lyrics.NewCache(config.DataPath(), config.LyricsCacheSize(), config.LyricsNotFoundTTL())
 	if err != nil {
-		log.Fatalf("invalid PORT value: %v", err)
-		return
+		log.Warn("failed to load the lyrics cache, starting with an empty one: ", err)
 	}
-	password, passwordFromEnv := getEnv("PASSWORD", config.Lavalink.Password)
-	if password == "" {
-		log.Fatal("missing 'PASSWORD'")
-		return
+	lyricsCache.OnSaveError = func(err error) {
+		log.Error("failed to save the lyrics cache: ", err)
 	}
 
-	securedStr, securedFromEnv := getEnv("SECURED", strconv.FormatBool(config.Lavalink.Secured))
-	secured, _ := strconv.ParseBool(securedStr)
-
-	fmt.Printf("Token (%s): %q\n", checkSource(tokenFromEnv), token)
-	fmt.Printf("GeniusToken (%s): %q\n", checkSource(geniusTokenFromEnv), geniusToken)
-	fmt.Printf("Lavalink:\n")
-	fmt.Printf("	Name (%s): %q\n", checkSource(nameFromEnv), name)
-	fmt.Printf("	Hostname (%s): %q\n", checkSource(hostNameFromEnv), hostName)
-	fmt.Printf("	Port (%s): %d\n", checkSource(portFromEnv), port)
-	fmt.Printf("	Password (%s): %q\n", checkSource(passwordFromEnv), password)
-	fmt.Printf("	Secured (%s): %v\n", checkSource(securedFromEnv), secured)
-	fmt.Printf("	SerachType (%s): %v\n", checkSource(searchTypeFromEnv), searchTypeStr)
+	history, err := bot.NewHistory(config.DataPath())
+	if err != nil {
+		log.Warn("failed to load the listening history: ", err)
+	}
 
 	b := &bot.Bot{
 		Queues: &bot.QueueManager{
 			Queues: make(map[string]*bot.Queue),
 		},
-		SearchType:  bot_config.ParseSearchType(searchTypeStr),
-		GeniusToken: geniusToken,
+		GuildSettings: guildSettings,
+		Playlists:     playlists,
+		LyricsCache:   lyricsCache,
+		History:       history,
+		Resolvers:     resolver.Default(&http.Client{Timeout: 15 * time.Second}),
 	}
 
-	session, err := discordgo.New("Bot " + token)
+	session, err := discordgo.New("Bot " + config.Token)
 	if err != nil {
 		log.Fatal(err)
 		return
@@ -97,7 +96,7 @@ func main() {
 	session.State.TrackVoice = true
 	session.Identify.Intents = discordgo.IntentGuilds | discordgo.IntentsGuildVoiceStates
 
-	session.AddHandler(b.OnApplicationCommand)
+	session.AddHandler(b.OnInteractionCreate)
 	session.AddHandler(b.OnVoiceServerUpdate)
 	session.AddHandler(b.OnVoiceStateUpdate)
 
@@ -119,77 +118,143 @@ func main() {
 		disgolink.WithListenerFunc(b.OnWebSocketClosed),
 	)
 	b.Handlers = map[string]func(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error{
-		"play":        b.Play,
-		"pause":       b.Pause,
-		"now-playing": b.NowPlaying,
-		"stop":        b.Stop,
-		"skip":        b.Skip,
-		"queue":       b.Queue,
-		"clear-queue": b.ClearQueue,
-		"queue-type":  b.QueueType,
-		"shuffle":     b.Shuffle,
-		"volume":      b.Volume,
-		"bass-boost":  b.BassBoost,
-		"eight-d":     b.EightD,
-		"lyrics":      b.Lyrics,
+		"play":          b.Play,
+		"playnext":      b.PlayNext,
+		"playnow":       b.PlayNow,
+		"Play in voice": b.PlayInVoice,
+		"Add to queue":  b.AddToQueue,
+		"radio":         b.Radio,
+		"playlist":      b.PlaylistCommand,
+		"search":        b.Search,
+		"pause":         b.Pause,
+		"now-playing":   b.NowPlaying,
+		"stop":          b.Stop,
+		"skip":          b.Skip,
+		"queue":         b.Queue,
+		"clear-queue":   b.ClearQueue,
+		"queue-type":    b.QueueType,
+		"shuffle":       b.Shuffle,
+		"volume":        b.Volume,
+		"bass-boost":    b.BassBoost,
+		"eight-d":       b.EightD,
+		"lyrics":        b.Lyrics,
+		"stats":         b.Stats,
+		"recap":         b.Recap,
+		"settings":      b.GuildSettingsCommand,
+	}
+	b.Autocompletes = map[string]func(event *discordgo.InteractionCreate, data discordgo.ApplicationCommandInteractionData) error{
+		"play":     b.SourceAutocomplete,
+		"playnext": b.SourceAutocomplete,
+		"playnow":  b.SourceAutocomplete,
+		"radio":    b.RadioAutocomplete,
+		"playlist": b.PlaylistAutocomplete,
+		"search":   b.SourceAutocomplete,
+	}
+	b.Components = map[string]func(event *discordgo.InteractionCreate, data discordgo.MessageComponentInteractionData) error{
+		"search":     b.SearchSelect,
+		"queue":      b.QueuePage,
+		"lyrics":     b.LyricsPage,
+		"lyricspick": b.LyricsPick,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	address := fmt.Sprintf("%s:%d", hostName, port)
-	node, err := b.Lavalink.AddNode(ctx, disgolink.NodeConfig{
-		Name:     name,
-		Address:  address,
-		Password: password,
-		Secure:   secured,
-	})
-	if err != nil {
+	if _, err := b.ApplyConfig(ctx, config); err != nil {
 		log.Fatal(err)
 		return
 	}
-	version, err := node.Version(ctx)
+	version, err := b.Lavalink.BestNode().Version(ctx)
 	if err != nil {
 		log.Fatal(err)
 		return
 	}
 	log.Infof("node version: %s", version)
+	b.CheckSearchSources(ctx)
 
 	log.Info("DiscordGo example is now running. Press CTRL-C to exit.")
+
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	reload := make(chan struct{}, 1)
+	go bot_config.Watch(watchCtx, configPath(), 5*time.Second, func() {
+		select {
+		case reload <- struct{}{}:
+		default:
+		}
+	})
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
 	s := make(chan os.Signal, 1)
 	signal.Notify(s, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
-	<-s
+	for {
+		select {
+		case <-s:
+			if err := b.LyricsCache.Flush(); err != nil {
+				log.Error("failed to save the lyrics cache: ", err)
+			}
+			return
+		case <-hup:
+			log.Info("received SIGHUP, reloading config")
+			reloadConfig(b)
+		case <-reload:
+			log.Info("config file changed, reloading config")
+			reloadConfig(b)
+		}
+	}
 }
 
-func loadConfig(filePath string) bot_config.Config {
-	embeded, err := build.GetEmbeddedConfig()
+// reloadConfig loads the config again and applies it to the running bot. An
+// invalid config is reported and the current one is kept.
+func reloadConfig(b *bot.Bot) {
+	config, sources, err := bot_config.Load(readEmbeddedConfig(), configPath())
 	if err != nil {
-		return bot_config.Config{}
+		log.Error("failed to reload config: ", err)
+		return
 	}
-
-	data, err := embeded.ReadFile(filePath)
-	if err != nil {
-		return bot_config.Config{}
+	for _, problem := range config.Validate() {
+		if problem.Severity == bot_config.SeverityError {
+			log.Error("config not reloaded: ", problem)
+			return
+		}
 	}
 
-	var config bot_config.Config
-	err = yaml.Unmarshal(data, &config)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	warnings, err := b.ApplyConfig(ctx, config)
+	for _, warning := range warnings {
+		log.Warn(warning)
+	}
 	if err != nil {
-		return config
+		log.Error("failed to apply config: ", err)
+		return
 	}
 
-	return config
+	config.WriteSummary(os.Stdout, sources)
+	b.CheckSearchSources(ctx)
+	log.Info("config reloaded")
 }
 
-func getEnv(key, fallback string) (string, bool) {
-	if value, exists := os.LookupEnv(key); exists {
-		return value, true
+// readEmbeddedConfig returns the config compiled into the binary, if any.
+func readEmbeddedConfig() []byte {
+	embeded, err := build.GetEmbeddedConfig()
+	if err != nil {
+		return nil
+	}
+
+	data, err := embeded.ReadFile(build.ConfigFile)
+	if err != nil {
+		return nil
 	}
-	return fallback, false
+	return data
 }
 
-func checkSource(fromEnv bool) string {
-	if fromEnv {
-		return "env"
+// configPath returns the config file to read from disk, which can be moved
+// with the CONFIG_FILE environment variable.
+func configPath() string {
+	if path, ok := os.LookupEnv("CONFIG_FILE"); ok {
+		return path
 	}
-	return "config"
+	return build.ConfigFile
 }